@@ -0,0 +1,191 @@
+package elf
+
+import (
+	"fmt"
+	"io"
+	"sort"
+)
+
+// AddressRange a contiguous range of the virtual address space described by
+// a PT_LOAD segment
+type AddressRange struct {
+	Start      uint64 /* First virtual address of the range */
+	End        uint64 /* One past the last virtual address of the range */
+	Offset     uint64 /* File offset of Start, only meaningful when FileBacked */
+	FileBacked bool   /* False for the zero filled (bss) tail of a segment */
+	Segment    int    /* Index of the program header the range comes from */
+}
+
+// AddressSpace view of the virtual address space the PT_LOAD segments of a
+// file set up, before any relocation
+type AddressSpace struct {
+	ranges    []AddressRange
+	segments  []ProgramHeader64
+	class     uint8
+	endianess uint8
+	notDumped bool /* Ranges past p_filesz were left out of a core rather than zero filled */
+}
+
+// AddressSpace builds the virtual address space of the file from its PT_LOAD segments
+func (e ELF64) AddressSpace() *AddressSpace {
+	return newAddressSpace(e.ProgramHeaders, CLASS64BIT, e.FileHeader.EIDATA)
+}
+
+// AddressSpace builds the virtual address space of the file from its PT_LOAD segments
+func (e ELF32) AddressSpace() *AddressSpace {
+	return newAddressSpace(e.Segments(), CLASS32BIT, e.FileHeader.EIDATA)
+}
+
+func newAddressSpace(pHead []ProgramHeader64, class uint8, endianess uint8) *AddressSpace {
+	a := &AddressSpace{segments: pHead, class: class, endianess: endianess}
+	for i, ph := range pHead {
+		if ph.PType != PTypeEncode["PT_LOAD"] || ph.PMemsz == 0 {
+			continue
+		}
+		filesz := ph.PFilesz
+		if filesz > ph.PMemsz {
+			filesz = ph.PMemsz
+		}
+		if filesz != 0 {
+			a.ranges = append(a.ranges, AddressRange{
+				Start: ph.PVaddr, End: ph.PVaddr + filesz, Offset: ph.POffset, FileBacked: true, Segment: i,
+			})
+		}
+		if filesz < ph.PMemsz {
+			a.ranges = append(a.ranges, AddressRange{
+				Start: ph.PVaddr + filesz, End: ph.PVaddr + ph.PMemsz, Segment: i,
+			})
+		}
+	}
+	sort.SliceStable(a.ranges, func(i, j int) bool {
+		return a.ranges[i].Start < a.ranges[j].Start
+	})
+	return a
+}
+
+// Ranges returns the mapped ranges of the address space sorted by address
+func (a *AddressSpace) Ranges() []AddressRange {
+	return a.ranges
+}
+
+// Lookup returns the range containing addr
+func (a *AddressSpace) Lookup(addr uint64) (AddressRange, error) {
+	i := sort.Search(len(a.ranges), func(i int) bool {
+		return a.ranges[i].End > addr
+	})
+	if i < len(a.ranges) && a.ranges[i].Start <= addr {
+		return a.ranges[i], nil
+	}
+	return AddressRange{}, fmt.Errorf("elf: address 0x%X: %w", addr, ErrUnmapped)
+}
+
+// Offset translates a virtual address to an offset in the file. Addresses in
+// the zero filled tail of a segment return ErrZeroFill
+func (a *AddressSpace) Offset(addr uint64) (uint64, error) {
+	r, err := a.Lookup(addr)
+	if err != nil {
+		return 0, err
+	}
+	if !r.FileBacked && a.notDumped {
+		return 0, fmt.Errorf("elf: address 0x%X: %w", addr, ErrNotDumped)
+	}
+	if !r.FileBacked {
+		return 0, fmt.Errorf("elf: address 0x%X: %w", addr, ErrZeroFill)
+	}
+	return r.Offset + (addr - r.Start), nil
+}
+
+// ReadAt reads len(p) bytes starting at virtual address addr. Zero filled
+// ranges read as zeros, unmapped addresses return ErrUnmapped, memory
+// left out of a core returns ErrNotDumped and segment data past the end of a
+// truncated file returns io.ErrUnexpectedEOF
+func (a *AddressSpace) ReadAt(p []byte, addr int64) (int, error) {
+	n := 0
+	for n < len(p) {
+		cur := uint64(addr) + uint64(n)
+		r, err := a.Lookup(cur)
+		if err != nil {
+			return n, err
+		}
+		avail := r.End - cur
+		want := uint64(len(p) - n)
+		if want > avail {
+			want = avail
+		}
+		if !r.FileBacked && a.notDumped {
+			return n, fmt.Errorf("elf: address 0x%X: %w", cur, ErrNotDumped)
+		}
+		if r.FileBacked {
+			data := a.segments[r.Segment].Data
+			start := cur - r.Start
+			if start+want > uint64(len(data)) {
+				// The file was cut short of the segment's p_filesz
+				if start < uint64(len(data)) {
+					n += copy(p[n:], data[start:])
+				}
+				return n, fmt.Errorf("elf: address 0x%X: %w", uint64(addr)+uint64(n), io.ErrUnexpectedEOF)
+			}
+			copy(p[n:], data[start:start+want])
+		} else {
+			for i := uint64(0); i < want; i++ {
+				p[uint64(n)+i] = 0
+			}
+		}
+		n += int(want)
+	}
+	return n, nil
+}
+
+// mapped returns the number of bytes mapped without a gap from addr on
+func (a *AddressSpace) mapped(addr uint64) uint64 {
+	n := uint64(0)
+	for cur := addr; ; {
+		r, err := a.Lookup(cur)
+		if err != nil {
+			return n
+		}
+		n += r.End - cur
+		cur = r.End
+	}
+}
+
+// checkMapped fails unless size bytes are mapped from addr on, letting callers
+// reject sizes taken from the file before allocating a buffer for them
+func (a *AddressSpace) checkMapped(addr uint64, size uint64) error {
+	if size > a.mapped(addr) {
+		return fmt.Errorf("elf: 0x%X bytes at address 0x%X: %w", size, addr, ErrUnmapped)
+	}
+	return nil
+}
+
+// ReadPointer reads a pointer sized, file endian value at addr
+func (a *AddressSpace) ReadPointer(addr uint64) (uint64, error) {
+	if a.class == CLASS32BIT {
+		buf := make([]byte, 4)
+		if _, err := a.ReadAt(buf, int64(addr)); err != nil {
+			return 0, err
+		}
+		return uint64(readu32Func(a.endianess)(buf, 0)), nil
+	}
+	buf := make([]byte, 8)
+	if _, err := a.ReadAt(buf, int64(addr)); err != nil {
+		return 0, err
+	}
+	return readu64Func(a.endianess)(buf, 0), nil
+}
+
+// ReadString reads the nul terminated string starting at addr
+func (a *AddressSpace) ReadString(addr uint64) (string, error) {
+	var str []byte
+	buf := make([]byte, 1)
+	for cur := addr; ; cur++ {
+		if _, err := a.ReadAt(buf, int64(cur)); err != nil {
+			return "", err
+		}
+		if buf[0] == 0 {
+			break
+		}
+		str = append(str, buf[0])
+	}
+	return string(str), nil
+}
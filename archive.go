@@ -0,0 +1,266 @@
+package elf
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	archiveMagic      = "!<arch>\n"
+	archiveHeaderSize = 60
+	archiveFileMagic  = "`\n"
+)
+
+// Archive a static library (ar archive) in GNU or BSD format
+type Archive struct {
+	Members []ArchiveMember /* Members in archive order, symbol index and long name tables excluded */
+	Symbols []ArchiveSymbol /* Symbol index from / or /SYM64/ (GNU) or __.SYMDEF (BSD), if present */
+}
+
+// ArchiveMember a file stored in an archive
+type ArchiveMember struct {
+	Name   string /* Member name, resolved through the long name table */
+	Offset int64  /* Offset of the member header in the archive */
+	Date   int64  /* Modification time, seconds since the epoch */
+	UID    int    /* Owner user id */
+	GID    int    /* Owner group id */
+	Mode   uint32 /* File mode */
+	Data   []byte /* Member contents */
+	File   File   /* Parsed member, an ELF64 or ELF32; nil for members that are not ELF */
+	Err    error  /* Why an ELF member could not be parsed */
+}
+
+// ArchiveSymbol an entry of the archive symbol index
+type ArchiveSymbol struct {
+	Name   string /* Symbol defined by the member */
+	Offset int64  /* Offset of the member header in the archive */
+	Member int    /* Index of the member in Members, -1 if Offset matches none */
+}
+
+// OpenArchive reads the archive at name
+func OpenArchive(name string) (*Archive, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return NewArchive(f, info.Size())
+}
+
+// OpenArchiveBytes reads an archive held in memory
+func OpenArchiveBytes(b []byte) (*Archive, error) {
+	return NewArchive(bytes.NewReader(b), int64(len(b)))
+}
+
+// NewArchive reads an archive of size bytes from r. ELF members are parsed, a
+// member that fails to parse records the error in its Err field rather than
+// failing the archive
+func NewArchive(r io.ReaderAt, size int64) (*Archive, error) {
+	magic := make([]byte, len(archiveMagic))
+	if err := checkedRead(r, magic, 0, "archive magic"); err != nil {
+		return nil, err
+	}
+	if string(magic) != archiveMagic {
+		return nil, formatErr(0, "archive magic", ErrNotArchive, "bad magic %q", magic)
+	}
+
+	a := &Archive{}
+	var longNames []byte
+	byOffset := map[int64]int{}
+	head := make([]byte, archiveHeaderSize)
+	for off := int64(len(archiveMagic)); off < size; {
+		// Some writers pad the end of the archive with a newline
+		if size-off < archiveHeaderSize {
+			break
+		}
+		if err := checkedRead(r, head, off, "archive member header"); err != nil {
+			return nil, err
+		}
+		if string(head[58:60]) != archiveFileMagic {
+			return nil, formatErr(off+58, "archive member header", nil, "bad terminator %q", head[58:60])
+		}
+		dataSize, err := strconv.ParseInt(strings.TrimSpace(string(head[48:58])), 10, 64)
+		if err != nil || dataSize < 0 {
+			return nil, formatErr(off+48, "archive member header", err, "bad size %q", head[48:58])
+		}
+		dataOff := off + archiveHeaderSize
+		if dataSize > size-dataOff {
+			return nil, formatErr(off+48, "archive member header", nil, "size 0x%X past end of archive", dataSize)
+		}
+		data := make([]byte, dataSize)
+		if err := checkedRead(r, data, dataOff, "archive member"); err != nil {
+			return nil, err
+		}
+
+		name := strings.TrimRight(string(head[0:16]), " ")
+		switch {
+		case name == "/" || name == "/SYM64/":
+			syms, err := gnuSymbolIndex(data, name == "/SYM64/", dataOff)
+			if err != nil {
+				return nil, err
+			}
+			a.Symbols = append(a.Symbols, syms...)
+		case name == "//":
+			longNames = data
+		default:
+			if name, data, err = archiveMemberName(name, data, longNames, off); err != nil {
+				return nil, err
+			}
+			if strings.HasPrefix(name, "__.SYMDEF") {
+				syms, err := bsdSymbolIndex(data, strings.HasPrefix(name, "__.SYMDEF_64"), dataOff)
+				if err != nil {
+					return nil, err
+				}
+				a.Symbols = append(a.Symbols, syms...)
+				break
+			}
+			m := ArchiveMember{Name: name, Offset: off, Data: data}
+			m.Date, _ = strconv.ParseInt(strings.TrimSpace(string(head[16:28])), 10, 64)
+			m.UID, _ = strconv.Atoi(strings.TrimSpace(string(head[28:34])))
+			m.GID, _ = strconv.Atoi(strings.TrimSpace(string(head[34:40])))
+			mode, _ := strconv.ParseUint(strings.TrimSpace(string(head[40:48])), 8, 32)
+			m.Mode = uint32(mode)
+			if len(data) >= 4 && readu32be(data, 0) == elfMagic {
+				m.File, m.Err = OpenBytes(data)
+			}
+			byOffset[off] = len(a.Members)
+			a.Members = append(a.Members, m)
+		}
+		off = dataOff + dataSize + dataSize%2
+	}
+
+	for i := range a.Symbols {
+		a.Symbols[i].Member = -1
+		if m, ok := byOffset[a.Symbols[i].Offset]; ok {
+			a.Symbols[i].Member = m
+		}
+	}
+	return a, nil
+}
+
+// archiveMemberName resolves GNU "/N" long names and "name/" terminators, and
+// BSD "#1/N" names stored ahead of the member data, which are cut from data
+func archiveMemberName(name string, data []byte, longNames []byte, off int64) (string, []byte, error) {
+	switch {
+	case strings.HasPrefix(name, "#1/"):
+		n, err := strconv.Atoi(name[3:])
+		if err != nil || n < 0 || n > len(data) {
+			return "", nil, formatErr(off, "archive member header", err, "bad BSD long name %q", name)
+		}
+		return strings.TrimRight(string(data[:n]), "\x00"), data[n:], nil
+	case len(name) > 1 && name[0] == '/':
+		start, err := strconv.Atoi(name[1:])
+		if err != nil || start < 0 || start >= len(longNames) {
+			return "", nil, formatErr(off, "archive member header", err, "long name %q out of range", name)
+		}
+		end := bytes.IndexByte(longNames[start:], '\n')
+		if end < 0 {
+			end = len(longNames) - start
+		}
+		return strings.TrimSuffix(string(longNames[start:start+end]), "/"), data, nil
+	}
+	return strings.TrimSuffix(name, "/"), data, nil
+}
+
+// gnuSymbolIndex decodes the big endian / (32 bit offsets) or /SYM64/ (64 bit
+// offsets) symbol index: a count, that many member offsets, then the names
+func gnuSymbolIndex(data []byte, sym64 bool, base int64) ([]ArchiveSymbol, error) {
+	width := uint64(4)
+	if sym64 {
+		width = 8
+	}
+	word := func(off uint64) uint64 {
+		if sym64 {
+			return readu64be(data, int(off))
+		}
+		return uint64(readu32be(data, int(off)))
+	}
+	if uint64(len(data)) < width {
+		return nil, formatErr(base, "archive symbol index", nil, "index of 0x%X bytes too small", len(data))
+	}
+	count := word(0)
+	if count > (uint64(len(data))-width)/width {
+		return nil, formatErr(base, "archive symbol index", nil, "0x%X symbols past end of index", count)
+	}
+	syms := make([]ArchiveSymbol, count)
+	strOff := int(width + count*width)
+	for i := range syms {
+		syms[i].Offset = int64(word(width + uint64(i)*width))
+		name, ok := readCStr(data, strOff)
+		if !ok {
+			return nil, formatErr(base+int64(strOff), "archive symbol index", nil, "symbol name %d past end of index", i)
+		}
+		syms[i].Name = name
+		strOff += len(name) + 1
+	}
+	return syms, nil
+}
+
+// bsdSymbolIndex decodes the little endian __.SYMDEF (32 bit) or __.SYMDEF_64
+// symbol index: the byte size of the ranlib array, the array of name offset and
+// member offset pairs, the byte size of the string table, then the names
+func bsdSymbolIndex(data []byte, sym64 bool, base int64) ([]ArchiveSymbol, error) {
+	width := uint64(4)
+	if sym64 {
+		width = 8
+	}
+	word := func(off uint64) uint64 {
+		if sym64 {
+			return readu64le(data, int(off))
+		}
+		return uint64(readu32le(data, int(off)))
+	}
+	size := uint64(len(data))
+	// The ranlib size and string table size words at the very least
+	if size < 2*width {
+		return nil, formatErr(base, "archive symbol index", nil, "index of 0x%X bytes too small", len(data))
+	}
+	ranlibSize := word(0)
+	if ranlibSize > size-2*width || ranlibSize%(2*width) != 0 {
+		return nil, formatErr(base, "archive symbol index", nil, "bad ranlib size 0x%X", ranlibSize)
+	}
+	strBase := 2*width + ranlibSize
+	strSize := word(width + ranlibSize)
+	if strSize > size-strBase {
+		return nil, formatErr(base+int64(width+ranlibSize), "archive symbol index", nil, "string table size 0x%X past end of index", strSize)
+	}
+	strtab := data[strBase : strBase+strSize]
+	syms := make([]ArchiveSymbol, ranlibSize/(2*width))
+	for i := range syms {
+		entry := width + uint64(i)*2*width
+		name, ok := readCStr(strtab, int(word(entry)))
+		if !ok {
+			return nil, formatErr(base+int64(entry), "archive symbol index", nil, "symbol name 0x%X out of range", word(entry))
+		}
+		syms[i] = ArchiveSymbol{Name: name, Offset: int64(word(entry + width))}
+	}
+	return syms, nil
+}
+
+// Member returns the first member called name
+func (a *Archive) Member(name string) (*ArchiveMember, error) {
+	for i := range a.Members {
+		if a.Members[i].Name == name {
+			return &a.Members[i], nil
+		}
+	}
+	return nil, fmt.Errorf("elf: archive member %q: %w", name, ErrNoMember)
+}
+
+// MemberForSymbol returns the member the symbol index says defines name
+func (a *Archive) MemberForSymbol(name string) (*ArchiveMember, error) {
+	for _, s := range a.Symbols {
+		if s.Name == name && s.Member >= 0 {
+			return &a.Members[s.Member], nil
+		}
+	}
+	return nil, fmt.Errorf("elf: archive symbol %q: %w", name, ErrSymbolNotFound)
+}
@@ -0,0 +1,389 @@
+package elf
+
+import "fmt"
+
+// AuxvDecode map of auxiliary vector entry types to friendly string
+var AuxvDecode = map[uint64]string{
+	0:  "AT_NULL",              /* End of vector */
+	1:  "AT_IGNORE",            /* Entry should be ignored */
+	2:  "AT_EXECFD",            /* File descriptor of program */
+	3:  "AT_PHDR",              /* Program headers for program */
+	4:  "AT_PHENT",             /* Size of program header entry */
+	5:  "AT_PHNUM",             /* Number of program headers */
+	6:  "AT_PAGESZ",            /* System page size */
+	7:  "AT_BASE",              /* Base address of interpreter */
+	8:  "AT_FLAGS",             /* Flags */
+	9:  "AT_ENTRY",             /* Entry point of program */
+	10: "AT_NOTELF",            /* Program is not ELF */
+	11: "AT_UID",               /* Real uid */
+	12: "AT_EUID",              /* Effective uid */
+	13: "AT_GID",               /* Real gid */
+	14: "AT_EGID",              /* Effective gid */
+	15: "AT_PLATFORM",          /* String identifying platform */
+	16: "AT_HWCAP",             /* Machine dependent hints about processor capabilities */
+	17: "AT_CLKTCK",            /* Frequency of times() */
+	23: "AT_SECURE",            /* Boolean, was exec setuid-like? */
+	24: "AT_BASE_PLATFORM",     /* String identifying real platform */
+	25: "AT_RANDOM",            /* Address of 16 random bytes */
+	26: "AT_HWCAP2",            /* More machine dependent hints about processor capabilities */
+	27: "AT_RSEQ_FEATURE_SIZE", /* rseq supported feature size */
+	28: "AT_RSEQ_ALIGN",        /* rseq allocation alignment */
+	29: "AT_HWCAP3",            /* Extension of AT_HWCAP */
+	30: "AT_HWCAP4",            /* Extension of AT_HWCAP */
+	31: "AT_EXECFN",            /* Filename of executable */
+	32: "AT_SYSINFO",           /* Entry point of the vsyscall page */
+	33: "AT_SYSINFO_EHDR",      /* Address of the vDSO */
+	51: "AT_MINSIGSTKSZ",       /* Minimal stack size for signal delivery */
+}
+
+// prstatusRegs names of the elf_gregset_t registers of NT_PRSTATUS, in
+// order, keyed by EMachine
+var prstatusRegs = map[uint16][]string{
+	0x3E: { /* struct user_regs_struct of x86-64 */
+		"r15", "r14", "r13", "r12", "rbp", "rbx", "r11", "r10", "r9", "r8",
+		"rax", "rcx", "rdx", "rsi", "rdi", "orig_rax", "rip", "cs", "eflags", "rsp",
+		"ss", "fs_base", "gs_base", "ds", "es", "fs", "gs",
+	},
+	0xB7: { /* struct user_pt_regs of AArch64 */
+		"x0", "x1", "x2", "x3", "x4", "x5", "x6", "x7", "x8", "x9",
+		"x10", "x11", "x12", "x13", "x14", "x15", "x16", "x17", "x18", "x19",
+		"x20", "x21", "x22", "x23", "x24", "x25", "x26", "x27", "x28", "x29",
+		"x30", "sp", "pc", "pstate",
+	},
+}
+
+// prstatusPCSP names of the program counter and stack pointer registers, keyed by EMachine
+var prstatusPCSP = map[uint16][2]string{
+	0x3E: {"rip", "rsp"},
+	0xB7: {"pc", "sp"},
+}
+
+// Register a named register value of a thread
+type Register struct {
+	Name  string
+	Value uint64
+}
+
+// PRStatus decoded NT_PRSTATUS descriptor, the state of one thread
+type PRStatus struct {
+	Signo     int32      /* Signal number, pr_info.si_signo */
+	Code      int32      /* Extra code, pr_info.si_code */
+	Errno     int32      /* Errno, pr_info.si_errno */
+	CurSig    uint16     /* Current signal */
+	SigPend   uint64     /* Set of pending signals */
+	SigHold   uint64     /* Set of held signals */
+	PID       uint32     /* Thread id */
+	PPID      uint32     /* Parent process id */
+	PGRP      uint32     /* Process group id */
+	SID       uint32     /* Session id */
+	UTime     [2]uint64  /* User time, seconds and microseconds */
+	STime     [2]uint64  /* System time, seconds and microseconds */
+	CUTime    [2]uint64  /* Cumulative user time */
+	CSTime    [2]uint64  /* Cumulative system time */
+	RawRegs   []byte     /* pr_reg, the general purpose registers as stored */
+	Registers []Register /* pr_reg decoded, only for x86-64 and AArch64 */
+	FPValid   uint32     /* True if math co-processor being used */
+	PC        uint64     /* Program counter, from Registers */
+	SP        uint64     /* Stack pointer, from Registers */
+}
+
+// PRPSInfo decoded NT_PRPSINFO descriptor, the state of the process
+type PRPSInfo struct {
+	State  uint8  /* Numeric process state */
+	SName  byte   /* Char for pr_state, e.g. 'R' */
+	Zomb   uint8  /* Zombie */
+	Nice   int8   /* Nice value */
+	Flag   uint64 /* Flags */
+	UID    uint32
+	GID    uint32
+	PID    uint32
+	PPID   uint32
+	PGRP   uint32
+	SID    uint32
+	FName  string /* Filename of executable */
+	PSArgs string /* Initial part of arg list */
+}
+
+// AuxvEntry entry of the NT_AUXV descriptor
+type AuxvEntry struct {
+	AType uint64 /* Entry type, see AuxvDecode */
+	AVal  uint64 /* Entry value */
+	Type  string /* Friendly name of AType */
+}
+
+// FileMapping entry of the NT_FILE descriptor, a file mapped into the process
+type FileMapping struct {
+	Start  uint64 /* First address of the mapping */
+	End    uint64 /* One past the last address of the mapping */
+	Offset uint64 /* Offset of Start in the file, in bytes */
+	Name   string /* Path of the mapped file */
+}
+
+// Core process state recorded in the notes and PT_LOAD segments of an ET_CORE file
+type Core struct {
+	Threads []PRStatus    /* One NT_PRSTATUS per thread, the faulting thread first */
+	Process *PRPSInfo     /* nil without a NT_PRPSINFO note */
+	Auxv    []AuxvEntry   /* Auxiliary vector of the process */
+	Files   []FileMapping /* Files mapped into the process */
+	Memory  *AddressSpace /* Process memory dumped into the PT_LOAD segments */
+}
+
+// Core decodes the notes and memory of an ET_CORE file
+func (e ELF64) Core() (*Core, error) {
+	return readCore(e)
+}
+
+// Core decodes the notes and memory of an ET_CORE file
+func (e ELF32) Core() (*Core, error) {
+	return readCore(e)
+}
+
+func readCore(f File) (*Core, error) {
+	header := f.Header()
+	if header.EType != etypeEncode["ET_CORE"] {
+		return nil, fmt.Errorf("elf: %s: %w", header.Type, ErrNotCore)
+	}
+	notes, err := segmentNotes(f)
+	if err != nil {
+		return nil, err
+	}
+
+	// The tail of a PT_LOAD segment in a core was not dumped rather than zero filled
+	c := &Core{Memory: newAddressSpace(f.Segments(), f.Class(), header.EIDATA)}
+	c.Memory.notDumped = true
+	for _, n := range notes {
+		if n.Name != "CORE" {
+			continue
+		}
+		switch n.Type {
+		case "NT_PRSTATUS":
+			s, err := n.PRStatus(header.EMachine)
+			if err != nil {
+				return nil, err
+			}
+			c.Threads = append(c.Threads, s)
+		case "NT_PRPSINFO":
+			p, err := n.PRPSInfo()
+			if err != nil {
+				return nil, err
+			}
+			c.Process = &p
+		case "NT_AUXV":
+			if c.Auxv, err = n.Auxv(); err != nil {
+				return nil, err
+			}
+		case "NT_FILE":
+			if c.Files, err = n.FileMappings(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return c, nil
+}
+
+// ReadAt reads process memory at virtual address addr
+func (c *Core) ReadAt(p []byte, addr int64) (int, error) {
+	return c.Memory.ReadAt(p, addr)
+}
+
+// AuxvValue returns the value of the first auxiliary vector entry of type atype
+func (c *Core) AuxvValue(atype string) (uint64, bool) {
+	for _, a := range c.Auxv {
+		if a.Type == atype {
+			return a.AVal, true
+		}
+	}
+	return 0, false
+}
+
+// Register returns the value of the named register
+func (s PRStatus) Register(name string) (uint64, bool) {
+	for _, r := range s.Registers {
+		if r.Name == name {
+			return r.Value, true
+		}
+	}
+	return 0, false
+}
+
+// PRStatus decodes a NT_PRSTATUS note, machine is the EMachine of the file
+// and selects the register layout
+func (n Note) PRStatus(machine uint16) (PRStatus, error) {
+	var s PRStatus
+	if n.Name != "CORE" || n.NType != NTCoreEncode["NT_PRSTATUS"] {
+		return s, fmt.Errorf("elf: note %s %s is not a prstatus", n.Name, n.Type)
+	}
+	u16 := readu16Func(n.endianess)
+	u32 := readu32Func(n.endianess)
+	word := n.word()
+	size := n.wordSize()
+
+	// pr_fpvalid is an int padded to the alignment of the structure
+	fpvalid := 4
+	if size == 8 {
+		fpvalid = 8
+	}
+	// struct elf_siginfo and pr_cursig, padded to the long pr_sigpend
+	off := 16
+	if len(n.Desc) < off+2*size+16+8*size+fpvalid {
+		return s, formatErr(0, "NT_PRSTATUS", nil, "descriptor is only 0x%X bytes", len(n.Desc))
+	}
+	s.Signo = int32(u32(n.Desc, 0x00))
+	s.Code = int32(u32(n.Desc, 0x04))
+	s.Errno = int32(u32(n.Desc, 0x08))
+	s.CurSig = u16(n.Desc, 0x0C)
+	s.SigPend = word(n.Desc, off)
+	s.SigHold = word(n.Desc, off+size)
+	off += 2 * size
+	s.PID = u32(n.Desc, off)
+	s.PPID = u32(n.Desc, off+4)
+	s.PGRP = u32(n.Desc, off+8)
+	s.SID = u32(n.Desc, off+12)
+	off += 16
+	for _, t := range []*[2]uint64{&s.UTime, &s.STime, &s.CUTime, &s.CSTime} {
+		t[0] = word(n.Desc, off)
+		t[1] = word(n.Desc, off+size)
+		off += 2 * size
+	}
+
+	// pr_reg runs up to pr_fpvalid, the last int of the structure
+	end := len(n.Desc) - fpvalid
+	s.RawRegs = n.Desc[off:end]
+	s.FPValid = u32(n.Desc, end)
+	if names, ok := prstatusRegs[machine]; ok && len(names)*size <= len(s.RawRegs) {
+		s.Registers = make([]Register, len(names))
+		for i, name := range names {
+			s.Registers[i] = Register{Name: name, Value: word(s.RawRegs, i*size)}
+		}
+		pcsp := prstatusPCSP[machine]
+		s.PC, _ = s.Register(pcsp[0])
+		s.SP, _ = s.Register(pcsp[1])
+	}
+	return s, nil
+}
+
+// PRPSInfo decodes a NT_PRPSINFO note
+func (n Note) PRPSInfo() (PRPSInfo, error) {
+	var p PRPSInfo
+	if n.Name != "CORE" || n.NType != NTCoreEncode["NT_PRPSINFO"] {
+		return p, fmt.Errorf("elf: note %s %s is not a prpsinfo", n.Name, n.Type)
+	}
+	u16 := readu16Func(n.endianess)
+	u32 := readu32Func(n.endianess)
+	size := n.wordSize()
+
+	// pr_flag is a long, the 32 bit ABIs use 16 bit uids
+	off := 4
+	uidSize := 2
+	if size == 8 {
+		off = 8
+		uidSize = 4
+	}
+	if len(n.Desc) < off+size+2*uidSize+16+16+80 {
+		return p, formatErr(0, "NT_PRPSINFO", nil, "descriptor is only 0x%X bytes", len(n.Desc))
+	}
+	p.State = n.Desc[0]
+	p.SName = n.Desc[1]
+	p.Zomb = n.Desc[2]
+	p.Nice = int8(n.Desc[3])
+	p.Flag = n.word()(n.Desc, off)
+	off += size
+	if uidSize == 4 {
+		p.UID = u32(n.Desc, off)
+		p.GID = u32(n.Desc, off+4)
+	} else {
+		p.UID = uint32(u16(n.Desc, off))
+		p.GID = uint32(u16(n.Desc, off+2))
+	}
+	off += 2 * uidSize
+	p.PID = u32(n.Desc, off)
+	p.PPID = u32(n.Desc, off+4)
+	p.PGRP = u32(n.Desc, off+8)
+	p.SID = u32(n.Desc, off+12)
+	off += 16
+	p.FName = fixedCStr(n.Desc[off : off+16])
+	p.PSArgs = fixedCStr(n.Desc[off+16 : off+16+80])
+	return p, nil
+}
+
+// Auxv decodes a NT_AUXV note, up to the AT_NULL entry
+func (n Note) Auxv() ([]AuxvEntry, error) {
+	if n.Name != "CORE" || n.NType != NTCoreEncode["NT_AUXV"] {
+		return nil, fmt.Errorf("elf: note %s %s is not an auxiliary vector", n.Name, n.Type)
+	}
+	word := n.word()
+	size := n.wordSize()
+	auxv := make([]AuxvEntry, 0)
+	for off := 0; off+2*size <= len(n.Desc); off += 2 * size {
+		a := AuxvEntry{AType: word(n.Desc, off), AVal: word(n.Desc, off+size)}
+		if a.AType == 0 {
+			break
+		}
+		a.Type = AuxvDecode[a.AType]
+		auxv = append(auxv, a)
+	}
+	return auxv, nil
+}
+
+// FileMappings decodes a NT_FILE note
+func (n Note) FileMappings() ([]FileMapping, error) {
+	if n.Name != "CORE" || n.NType != NTCoreEncode["NT_FILE"] {
+		return nil, fmt.Errorf("elf: note %s %s is not a file mapping", n.Name, n.Type)
+	}
+	word := n.word()
+	size := n.wordSize()
+	if len(n.Desc) < 2*size {
+		return nil, formatErr(0, "NT_FILE", nil, "descriptor is only 0x%X bytes", len(n.Desc))
+	}
+	count := word(n.Desc, 0)
+	pageSize := word(n.Desc, size)
+	if count > uint64((len(n.Desc)-2*size)/(3*size)) {
+		return nil, formatErr(0, "NT_FILE", nil, "0x%X mappings past end of descriptor", count)
+	}
+
+	files := make([]FileMapping, count)
+	off := 2 * size
+	for i := range files {
+		files[i].Start = word(n.Desc, off)
+		files[i].End = word(n.Desc, off+size)
+		files[i].Offset = word(n.Desc, off+2*size) * pageSize
+		off += 3 * size
+	}
+	for i := range files {
+		name, ok := readCStr(n.Desc, off)
+		if !ok {
+			return nil, formatErr(0, "NT_FILE", nil, "file name 0x%X past end of descriptor", i)
+		}
+		files[i].Name = name
+		off += len(name) + 1
+	}
+	return files, nil
+}
+
+func (n Note) wordSize() int {
+	if n.class == CLASS32BIT {
+		return 4
+	}
+	return 8
+}
+
+// word returns a reader for the native long of the note's file
+func (n Note) word() func([]byte, int) uint64 {
+	if n.class == CLASS32BIT {
+		u32 := readu32Func(n.endianess)
+		return func(buf []byte, off int) uint64 {
+			return uint64(u32(buf, off))
+		}
+	}
+	return readu64Func(n.endianess)
+}
+
+// fixedCStr returns the nul terminated string in a fixed size field
+func fixedCStr(buf []byte) string {
+	for i, c := range buf {
+		if c == 0 {
+			return string(buf[:i])
+		}
+	}
+	return string(buf)
+}
@@ -0,0 +1,62 @@
+package elf
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// fileNote builds a 64 bit little endian NT_FILE note: the mapping count, the
+// page size, a start, end and page offset triple per mapping, then names
+func fileNote(count uint64, words []uint64, names string) Note {
+	desc := make([]byte, 16+8*len(words), 16+8*len(words)+len(names))
+	binary.LittleEndian.PutUint64(desc, count)
+	binary.LittleEndian.PutUint64(desc[8:], 0x1000)
+	for i, w := range words {
+		binary.LittleEndian.PutUint64(desc[16+8*i:], w)
+	}
+	desc = append(desc, names...)
+	return Note{Name: "CORE", NType: NTCoreEncode["NT_FILE"], Desc: desc, class: CLASS64BIT, endianess: litteEndian}
+}
+
+func TestFileMappings(t *testing.T) {
+	n := fileNote(2, []uint64{0x400000, 0x401000, 0, 0x7f0000, 0x7f2000, 3}, "/bin/true\x00/lib/libc.so.6\x00")
+	files, err := n.FileMappings()
+	if err != nil {
+		t.Fatalf("FileMappings: %v", err)
+	}
+	want := []FileMapping{
+		{Start: 0x400000, End: 0x401000, Offset: 0, Name: "/bin/true"},
+		{Start: 0x7f0000, End: 0x7f2000, Offset: 0x3000, Name: "/lib/libc.so.6"},
+	}
+	if len(files) != len(want) {
+		t.Fatalf("got %d mappings, want %d", len(files), len(want))
+	}
+	for i := range want {
+		if files[i] != want[i] {
+			t.Errorf("mapping %d is %+v, want %+v", i, files[i], want[i])
+		}
+	}
+}
+
+func TestFileMappingsMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		note Note
+	}{
+		{"descriptor shorter than the header", Note{Name: "CORE", NType: NTCoreEncode["NT_FILE"], Desc: make([]byte, 12), class: CLASS64BIT}},
+		{"mapping past end of 24 byte descriptor", fileNote(1, []uint64{0x400000}, "")},
+		{"mapping past end of 32 byte descriptor", fileNote(1, []uint64{0x400000, 0x401000}, "")},
+		{"count wraps", fileNote(1<<62, []uint64{0x400000, 0x401000, 0}, "")},
+		{"file name past end", fileNote(1, []uint64{0x400000, 0x401000, 0}, "/bin/true")},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := tt.note.FileMappings()
+			var fe *FormatError
+			if !errors.As(err, &fe) {
+				t.Fatalf("got error %v, want a FormatError", err)
+			}
+		})
+	}
+}
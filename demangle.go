@@ -0,0 +1,49 @@
+package elf
+
+import (
+	"strings"
+
+	"github.com/ianlancetaylor/demangle"
+)
+
+// NameStyle selects how symbol names are shown
+type NameStyle int
+
+const (
+	NameRaw       NameStyle = iota /* The name as stored in the file */
+	NameDemangled                  /* Demangled C++ and Rust names, others as stored */
+	NameBoth                       /* The demangled name followed by the stored one in brackets */
+)
+
+// Demangle returns the readable form of an Itanium C++ or Rust (legacy or v0)
+// mangled name. Other names, and names that fail to demangle, are returned as is
+func Demangle(name string) string {
+	// Names taken from versioned symbol tables may carry @VERSION or @@VERSION
+	if i := strings.IndexByte(name, '@'); i > 0 {
+		return demangle.Filter(name[:i]) + name[i:]
+	}
+	return demangle.Filter(name)
+}
+
+// FormatName returns name in the given style
+func FormatName(name string, style NameStyle) string {
+	switch style {
+	case NameDemangled:
+		return Demangle(name)
+	case NameBoth:
+		if demangled := Demangle(name); demangled != name {
+			return demangled + " [" + name + "]"
+		}
+	}
+	return name
+}
+
+// Demangled returns the demangled name of the symbol
+func (s Symbol) Demangled() string {
+	return Demangle(s.Name)
+}
+
+// DisplayName returns the name of the symbol in the given style
+func (s Symbol) DisplayName(style NameStyle) string {
+	return FormatName(s.Name, style)
+}
@@ -16,8 +16,10 @@ var archBitMap = map[int]int{
 // Disassembler provides methods for disassembling executable code held in buffer
 type Disassembler struct {
 	Buf       []byte
-	StartAddr uint64 /* Starting addr of first instruction */
-	Arch      int    /* Either 32 or 64 */
+	StartAddr uint64    /* Starting addr of first instruction */
+	Arch      int       /* Either 32 or 64 */
+	Symbols   []Symbol  /* Optional, instructions at a symbol's address are labelled with its name */
+	NameStyle NameStyle /* How labels are shown */
 }
 
 // Disasm disassembly instructions in buffer
@@ -43,10 +45,95 @@ func (d Disassembler) Disasm() string {
 		return disassembled
 	}
 
+	labels := d.labels()
 	var b strings.Builder
 	for _, instr := range instructions {
+		if label, ok := labels[uint64(instr.Address)]; ok {
+			fmt.Fprintf(&b, "\n0x%x <%s>:\n", instr.Address, label)
+		}
 		fmt.Fprintf(&b, "0x%x:\t% 29x\t\t%s\t\t%s\n", instr.Address, instr.Bytes, instr.Mnemonic, instr.OpStr)
 	}
 	disassembled = b.String()
 	return disassembled
 }
+
+// labels maps the addresses of function and untyped symbols inside the buffer
+// to their names, preferring functions and global bindings when several share
+// an address
+func (d Disassembler) labels() map[uint64]string {
+	rank := func(s Symbol) int {
+		r := 0
+		if s.Type == "STT_FUNC" {
+			r += 2
+		}
+		if s.Binding != "STB_LOCAL" {
+			r++
+		}
+		return r
+	}
+	best := map[uint64]Symbol{}
+	end := d.StartAddr + uint64(len(d.Buf))
+	for _, s := range d.Symbols {
+		if s.Name == "" || s.SectionIndex == 0 || s.STValue < d.StartAddr || s.STValue >= end {
+			continue
+		}
+		if s.Type != "STT_FUNC" && s.Type != "STT_NOTYPE" {
+			continue
+		}
+		if cur, ok := best[s.STValue]; !ok || rank(s) > rank(cur) {
+			best[s.STValue] = s
+		}
+	}
+	labels := make(map[uint64]string, len(best))
+	for addr, s := range best {
+		labels[addr] = s.DisplayName(d.NameStyle)
+	}
+	return labels
+}
+
+// DisassembleSection disassembles the section at index from its virtual address,
+// labelling the symbols defined in it with their names in the given style
+func (e ELF64) DisassembleSection(index int, style NameStyle) string {
+	return disassembleSection(e, index, style)
+}
+
+// DisassembleSection disassembles the section at index from its virtual address,
+// labelling the symbols defined in it with their names in the given style
+func (e ELF32) DisassembleSection(index int, style NameStyle) string {
+	return disassembleSection(e, index, style)
+}
+
+func disassembleSection(f File, index int, style NameStyle) string {
+	sHead, sections := f.SectionTable(), f.SectionData()
+	if index < 0 || index >= len(sHead) || index >= len(sections) {
+		return ""
+	}
+	symbols, err := f.Symbols()
+	if err != nil {
+		symbols, _ = f.DynamicSymbols()
+	}
+	var inSection []Symbol
+	for _, s := range symbols {
+		if s.SectionIndex == uint32(index) {
+			inSection = append(inSection, s)
+		}
+	}
+	if plt, err := readPLT(f); err == nil {
+		for _, e := range plt.Entries {
+			if e.Section == sHead[index].SectionName {
+				inSection = append(inSection, Symbol{
+					Name: e.Name + "@plt", STValue: e.Address, STSize: e.Size,
+					Type: "STT_FUNC", Binding: "STB_GLOBAL", SectionIndex: uint32(index),
+				})
+			}
+		}
+	}
+	dis := Disassembler{
+		Buf:       sections[index].Data,
+		StartAddr: sHead[index].SHAddr,
+		Arch:      sections[index].archBits,
+		Symbols:   inSection,
+		NameStyle: style,
+	}
+	return dis.Disasm()
+}
@@ -0,0 +1,923 @@
+package elf
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+)
+
+// DWARF tags used by the decoder
+const (
+	dwTagCompileUnit       = 0x11
+	dwTagPartialUnit       = 0x3c
+	dwTagSkeletonUnit      = 0x4a
+	dwTagSubprogram        = 0x2e
+	dwTagInlinedSubroutine = 0x1d
+)
+
+// DWARF attributes used by the decoder
+const (
+	dwAtName            = 0x03
+	dwAtStmtList        = 0x10
+	dwAtLowPC           = 0x11
+	dwAtHighPC          = 0x12
+	dwAtLanguage        = 0x13
+	dwAtCompDir         = 0x1b
+	dwAtProducer        = 0x25
+	dwAtAbstractOrigin  = 0x31
+	dwAtDeclFile        = 0x3a
+	dwAtDeclLine        = 0x3b
+	dwAtSpecification   = 0x47
+	dwAtRanges          = 0x55
+	dwAtLinkageName     = 0x6e
+	dwAtStrOffsetsBase  = 0x72
+	dwAtAddrBase        = 0x73
+	dwAtRnglistsBase    = 0x74
+	dwAtMIPSLinkageName = 0x2007
+	dwAtGNUAddrBase     = 0x2133
+)
+
+// DWARF attribute forms
+const (
+	dwFormAddr          = 0x01
+	dwFormBlock2        = 0x03
+	dwFormBlock4        = 0x04
+	dwFormData2         = 0x05
+	dwFormData4         = 0x06
+	dwFormData8         = 0x07
+	dwFormString        = 0x08
+	dwFormBlock         = 0x09
+	dwFormBlock1        = 0x0a
+	dwFormData1         = 0x0b
+	dwFormFlag          = 0x0c
+	dwFormSdata         = 0x0d
+	dwFormStrp          = 0x0e
+	dwFormUdata         = 0x0f
+	dwFormRefAddr       = 0x10
+	dwFormRef1          = 0x11
+	dwFormRef2          = 0x12
+	dwFormRef4          = 0x13
+	dwFormRef8          = 0x14
+	dwFormRefUdata      = 0x15
+	dwFormIndirect      = 0x16
+	dwFormSecOffset     = 0x17
+	dwFormExprloc       = 0x18
+	dwFormFlagPresent   = 0x19
+	dwFormStrx          = 0x1a
+	dwFormAddrx         = 0x1b
+	dwFormRefSup4       = 0x1c
+	dwFormStrpSup       = 0x1d
+	dwFormData16        = 0x1e
+	dwFormLineStrp      = 0x1f
+	dwFormRefSig8       = 0x20
+	dwFormImplicitConst = 0x21
+	dwFormLoclistx      = 0x22
+	dwFormRnglistx      = 0x23
+	dwFormRefSup8       = 0x24
+	dwFormStrx1         = 0x25
+	dwFormStrx2         = 0x26
+	dwFormStrx3         = 0x27
+	dwFormStrx4         = 0x28
+	dwFormAddrx1        = 0x29
+	dwFormAddrx2        = 0x2a
+	dwFormAddrx3        = 0x2b
+	dwFormAddrx4        = 0x2c
+	dwFormGNUAddrIndex  = 0x1f01
+	dwFormGNUStrIndex   = 0x1f02
+	dwFormGNURefAlt     = 0x1f20
+	dwFormGNUStrpAlt    = 0x1f21
+)
+
+// DWARF 5 range list entries
+const (
+	dwRLEEndOfList    = 0x0
+	dwRLEBaseAddressx = 0x1
+	dwRLEStartxEndx   = 0x2
+	dwRLEStartxLength = 0x3
+	dwRLEOffsetPair   = 0x4
+	dwRLEBaseAddress  = 0x5
+	dwRLEStartEnd     = 0x6
+	dwRLEStartLength  = 0x7
+)
+
+// dwarfBuf cursor over a DWARF section. Reads past the end set err and
+// return zero values, callers check err once per structure
+type dwarfBuf struct {
+	data      []byte
+	off       int
+	endianess uint8
+	dwarf64   bool
+	err       error
+	name      string
+}
+
+func (b *dwarfBuf) fail(format string, args ...interface{}) {
+	if b.err == nil {
+		b.err = formatErr(int64(b.off), b.name, nil, format, args...)
+	}
+}
+
+func (b *dwarfBuf) need(n int) bool {
+	if b.err != nil {
+		return false
+	}
+	if n < 0 || b.off < 0 || b.off+n > len(b.data) {
+		b.fail("read of 0x%X bytes past end of section", n)
+		return false
+	}
+	return true
+}
+
+func (b *dwarfBuf) u8() uint8 {
+	if !b.need(1) {
+		return 0
+	}
+	b.off++
+	return b.data[b.off-1]
+}
+
+func (b *dwarfBuf) u16() uint16 {
+	if !b.need(2) {
+		return 0
+	}
+	b.off += 2
+	return readu16Func(b.endianess)(b.data, b.off-2)
+}
+
+func (b *dwarfBuf) u24() uint32 {
+	if !b.need(3) {
+		return 0
+	}
+	b.off += 3
+	x := b.data[b.off-3 : b.off]
+	if b.endianess == bigEndian {
+		return uint32(x[0])<<16 | uint32(x[1])<<8 | uint32(x[2])
+	}
+	return uint32(x[2])<<16 | uint32(x[1])<<8 | uint32(x[0])
+}
+
+func (b *dwarfBuf) u32() uint32 {
+	if !b.need(4) {
+		return 0
+	}
+	b.off += 4
+	return readu32Func(b.endianess)(b.data, b.off-4)
+}
+
+func (b *dwarfBuf) u64() uint64 {
+	if !b.need(8) {
+		return 0
+	}
+	b.off += 8
+	return readu64Func(b.endianess)(b.data, b.off-8)
+}
+
+func (b *dwarfBuf) uleb() uint64 {
+	var val uint64
+	var shift uint
+	for {
+		c := b.u8()
+		if b.err != nil {
+			return 0
+		}
+		if shift < 64 {
+			val |= uint64(c&0x7f) << shift
+		}
+		shift += 7
+		if c&0x80 == 0 {
+			return val
+		}
+	}
+}
+
+func (b *dwarfBuf) sleb() int64 {
+	var val int64
+	var shift uint
+	for {
+		c := b.u8()
+		if b.err != nil {
+			return 0
+		}
+		if shift < 64 {
+			val |= int64(c&0x7f) << shift
+		}
+		shift += 7
+		if c&0x80 == 0 {
+			if shift < 64 && c&0x40 != 0 {
+				val |= -1 << shift
+			}
+			return val
+		}
+	}
+}
+
+func (b *dwarfBuf) cstr() string {
+	str, ok := readCStr(b.data, b.off)
+	if !ok {
+		b.fail("unterminated string")
+		return ""
+	}
+	b.off += len(str) + 1
+	return str
+}
+
+func (b *dwarfBuf) skip(n int) {
+	if b.need(n) {
+		b.off += n
+	}
+}
+
+// offset reads a section offset, 4 or 8 bytes depending on the DWARF format
+func (b *dwarfBuf) offset() uint64 {
+	if b.dwarf64 {
+		return b.u64()
+	}
+	return uint64(b.u32())
+}
+
+func (b *dwarfBuf) addr(size uint8) uint64 {
+	switch size {
+	case 1:
+		return uint64(b.u8())
+	case 2:
+		return uint64(b.u16())
+	case 4:
+		return uint64(b.u32())
+	case 8:
+		return b.u64()
+	}
+	b.fail("unsupported address size 0x%X", size)
+	return 0
+}
+
+// unitLength reads an initial length field, switching to the 64 bit DWARF
+// format when it is the 0xffffffff escape. It returns the offset of the end
+// of the unit
+func (b *dwarfBuf) unitLength() int {
+	length := uint64(b.u32())
+	b.dwarf64 = false
+	if length == 0xffffffff {
+		b.dwarf64 = true
+		length = b.u64()
+	}
+	if b.err == nil && length > uint64(len(b.data)-b.off) {
+		b.fail("unit length 0x%X past end of section", length)
+		return len(b.data)
+	}
+	return b.off + int(length)
+}
+
+type dwarfAttrSpec struct {
+	attr     uint64
+	form     uint64
+	implicit int64
+}
+
+type dwarfAbbrev struct {
+	tag      uint64
+	children bool
+	attrs    []dwarfAttrSpec
+}
+
+// dwarfValue a raw attribute value, resolved once the unit's base attributes are known
+type dwarfValue struct {
+	form uint64
+	val  uint64
+	str  string
+}
+
+// CompileUnit a compilation unit of .debug_info
+type CompileUnit struct {
+	Offset   uint64      /* Offset of the unit header in .debug_info */
+	Version  uint16      /* DWARF version */
+	Name     string      /* DW_AT_name, usually the primary source file */
+	CompDir  string      /* DW_AT_comp_dir */
+	Producer string      /* DW_AT_producer */
+	Language uint64      /* DW_AT_language */
+	Ranges   [][2]uint64 /* Address ranges covered by the unit, [low, high) */
+
+	addrSize      uint8
+	dwarf64       bool
+	stmtList      uint64
+	hasStmtList   bool
+	strOffsetBase uint64
+	addrBase      uint64
+	rnglistsBase  uint64
+	lines         []LineEntry
+	linesDecoded  bool
+}
+
+// Function a subprogram DIE with code
+type Function struct {
+	Name     string      /* DW_AT_name, following DW_AT_specification and DW_AT_abstract_origin */
+	Linkage  string      /* DW_AT_linkage_name, the mangled name, if any */
+	Ranges   [][2]uint64 /* Address ranges of the function, [low, high) */
+	DeclFile uint64      /* Index of the declaring file in the unit's line table */
+	DeclLine uint64      /* Declaring line */
+	Unit     *CompileUnit
+
+	offset    uint64
+	reference uint64 /* Offset of the DIE named by DW_AT_specification or DW_AT_abstract_origin */
+}
+
+// DWARF decoded debugging information of a file
+type DWARF struct {
+	Units     []*CompileUnit
+	Functions []*Function
+
+	sections  map[string][]byte
+	endianess uint8
+	names     map[uint64]dieName
+	byAddr    []functionRange /* Ranges of every function, sorted by start address */
+}
+
+type functionRange struct {
+	start, end uint64
+	fn         *Function
+}
+
+type dieName struct {
+	name      string
+	linkage   string
+	reference uint64
+}
+
+// DWARF decodes the compile units, functions and line tables of the .debug_ sections
+func (e ELF64) DWARF() (*DWARF, error) {
+	return newDWARF(e)
+}
+
+// DWARF decodes the compile units, functions and line tables of the .debug_ sections
+func (e ELF32) DWARF() (*DWARF, error) {
+	return newDWARF(e)
+}
+
+func newDWARF(f File) (*DWARF, error) {
+	d := &DWARF{
+		sections:  make(map[string][]byte),
+		endianess: f.Header().EIDATA,
+		names:     make(map[uint64]dieName),
+	}
+	sections := f.SectionData()
+	for i, h := range f.SectionTable() {
+		name := h.SectionName
+		if strings.HasPrefix(name, ".zdebug_") {
+			name = ".debug_" + name[8:]
+		} else if !strings.HasPrefix(name, ".debug_") {
+			continue
+		}
+		data, err := sections[i].Decompressed()
+		if err != nil {
+			return nil, err
+		}
+		d.sections[name] = data
+	}
+	if _, ok := d.sections[".debug_info"]; !ok {
+		return nil, ErrNoDWARF
+	}
+	if err := d.readUnits(); err != nil {
+		return nil, err
+	}
+	d.resolveNames()
+	return d, nil
+}
+
+func (d *DWARF) buf(name string, off uint64) *dwarfBuf {
+	b := &dwarfBuf{data: d.sections[name], endianess: d.endianess, name: name}
+	if off > uint64(len(b.data)) {
+		b.fail("offset 0x%X past end of section", off)
+		return b
+	}
+	b.off = int(off)
+	return b
+}
+
+func (d *DWARF) readAbbrevs(off uint64) (map[uint64]dwarfAbbrev, error) {
+	b := d.buf(".debug_abbrev", off)
+	abbrevs := make(map[uint64]dwarfAbbrev)
+	for {
+		code := b.uleb()
+		if code == 0 || b.err != nil {
+			break
+		}
+		var a dwarfAbbrev
+		a.tag = b.uleb()
+		a.children = b.u8() != 0
+		for {
+			var spec dwarfAttrSpec
+			spec.attr = b.uleb()
+			spec.form = b.uleb()
+			if spec.form == dwFormImplicitConst {
+				spec.implicit = b.sleb()
+			}
+			if (spec.attr == 0 && spec.form == 0) || b.err != nil {
+				break
+			}
+			a.attrs = append(a.attrs, spec)
+		}
+		abbrevs[code] = a
+	}
+	return abbrevs, b.err
+}
+
+func (d *DWARF) readUnits() error {
+	b := d.buf(".debug_info", 0)
+	abbrevCache := make(map[uint64]map[uint64]dwarfAbbrev)
+	for b.off < len(b.data) {
+		cu := &CompileUnit{Offset: uint64(b.off)}
+		end := b.unitLength()
+		cu.dwarf64 = b.dwarf64
+		cu.Version = b.u16()
+		var abbrevOff uint64
+		unitType := uint8(1)
+		if cu.Version >= 5 {
+			unitType = b.u8()
+			cu.addrSize = b.u8()
+			abbrevOff = b.offset()
+			switch unitType {
+			case 4, 5: /* DW_UT_skeleton, DW_UT_split_compile */
+				b.skip(8)
+			case 2, 6: /* DW_UT_type, DW_UT_split_type */
+				b.skip(8)
+				b.offset()
+			}
+		} else {
+			abbrevOff = b.offset()
+			cu.addrSize = b.u8()
+		}
+		if b.err != nil {
+			return b.err
+		}
+		if cu.Version < 2 || cu.Version > 5 {
+			return formatErr(int64(cu.Offset), ".debug_info", nil, "unsupported DWARF version %d", cu.Version)
+		}
+
+		abbrevs, ok := abbrevCache[abbrevOff]
+		if !ok {
+			var err error
+			if abbrevs, err = d.readAbbrevs(abbrevOff); err != nil {
+				return err
+			}
+			abbrevCache[abbrevOff] = abbrevs
+		}
+		if err := d.readDIEs(b, end, cu, abbrevs); err != nil {
+			return err
+		}
+		if unitType == 1 || unitType == 3 || unitType == 4 {
+			d.Units = append(d.Units, cu)
+		}
+		b.off = end
+	}
+	return nil
+}
+
+type dwarfDIE struct {
+	offset uint64
+	tag    uint64
+	attrs  map[uint64]dwarfValue
+}
+
+func (d *DWARF) readDIEs(b *dwarfBuf, end int, cu *CompileUnit, abbrevs map[uint64]dwarfAbbrev) error {
+	var dies []dwarfDIE
+	for b.off < end {
+		offset := uint64(b.off)
+		code := b.uleb()
+		if b.err != nil {
+			return b.err
+		}
+		if code == 0 {
+			continue
+		}
+		a, ok := abbrevs[code]
+		if !ok {
+			return formatErr(int64(offset), ".debug_info", nil, "unknown abbreviation code 0x%X", code)
+		}
+		die := dwarfDIE{offset: offset, tag: a.tag}
+		keep := a.tag == dwTagCompileUnit || a.tag == dwTagPartialUnit || a.tag == dwTagSkeletonUnit ||
+			a.tag == dwTagSubprogram || a.tag == dwTagInlinedSubroutine
+		if keep {
+			die.attrs = make(map[uint64]dwarfValue)
+		}
+		for _, spec := range a.attrs {
+			v := d.readValue(b, cu, spec.form, spec.implicit)
+			if b.err != nil {
+				return b.err
+			}
+			if keep {
+				die.attrs[spec.attr] = v
+			} else if spec.attr == dwAtName || spec.attr == dwAtLinkageName || spec.attr == dwAtMIPSLinkageName {
+				// Names of other DIEs are needed for DW_AT_specification targets
+				if die.attrs == nil {
+					die.attrs = make(map[uint64]dwarfValue)
+				}
+				die.attrs[spec.attr] = v
+			}
+		}
+		if die.attrs != nil {
+			dies = append(dies, die)
+		}
+	}
+
+	// Base attributes of the unit DIE apply to strx and addrx forms anywhere
+	// in the unit, including the unit DIE itself
+	if len(dies) > 0 && (dies[0].tag == dwTagCompileUnit || dies[0].tag == dwTagPartialUnit || dies[0].tag == dwTagSkeletonUnit) {
+		attrs := dies[0].attrs
+		if v, ok := attrs[dwAtStrOffsetsBase]; ok {
+			cu.strOffsetBase = v.val
+		} else if cu.Version >= 5 {
+			cu.strOffsetBase = 8
+			if cu.dwarf64 {
+				cu.strOffsetBase = 16
+			}
+		}
+		if v, ok := attrs[dwAtAddrBase]; ok {
+			cu.addrBase = v.val
+		} else if v, ok := attrs[dwAtGNUAddrBase]; ok {
+			cu.addrBase = v.val
+		}
+		if v, ok := attrs[dwAtRnglistsBase]; ok {
+			cu.rnglistsBase = v.val
+		}
+		cu.Name = d.str(cu, attrs[dwAtName])
+		cu.CompDir = d.str(cu, attrs[dwAtCompDir])
+		cu.Producer = d.str(cu, attrs[dwAtProducer])
+		cu.Language = attrs[dwAtLanguage].val
+		if v, ok := attrs[dwAtStmtList]; ok {
+			cu.stmtList = v.val
+			cu.hasStmtList = true
+		}
+		var err error
+		if cu.Ranges, err = d.ranges(cu, attrs, 0); err != nil {
+			return err
+		}
+	}
+
+	// Range lists of nested DIEs are relative to the unit's DW_AT_low_pc
+	var base uint64
+	if len(dies) > 0 {
+		if v, ok := dies[0].attrs[dwAtLowPC]; ok {
+			base = d.addr(cu, v)
+		}
+	}
+	for _, die := range dies {
+		name := dieName{
+			name:    d.str(cu, die.attrs[dwAtName]),
+			linkage: d.str(cu, die.attrs[dwAtLinkageName]),
+		}
+		if name.linkage == "" {
+			name.linkage = d.str(cu, die.attrs[dwAtMIPSLinkageName])
+		}
+		if v, ok := die.attrs[dwAtSpecification]; ok {
+			name.reference = d.ref(cu, v)
+		} else if v, ok := die.attrs[dwAtAbstractOrigin]; ok {
+			name.reference = d.ref(cu, v)
+		}
+		d.names[die.offset] = name
+
+		if die.tag != dwTagSubprogram {
+			continue
+		}
+		ranges, err := d.ranges(cu, die.attrs, base)
+		if err != nil {
+			return err
+		}
+		if len(ranges) == 0 {
+			continue
+		}
+		d.Functions = append(d.Functions, &Function{
+			Ranges:    ranges,
+			DeclFile:  die.attrs[dwAtDeclFile].val,
+			DeclLine:  die.attrs[dwAtDeclLine].val,
+			Unit:      cu,
+			offset:    die.offset,
+			reference: name.reference,
+		})
+	}
+	return nil
+}
+
+func (d *DWARF) readValue(b *dwarfBuf, cu *CompileUnit, form uint64, implicit int64) dwarfValue {
+	v := dwarfValue{form: form}
+	switch form {
+	case dwFormAddr:
+		v.val = b.addr(cu.addrSize)
+	case dwFormData1, dwFormRef1, dwFormFlag, dwFormStrx1, dwFormAddrx1:
+		v.val = uint64(b.u8())
+	case dwFormData2, dwFormRef2, dwFormStrx2, dwFormAddrx2:
+		v.val = uint64(b.u16())
+	case dwFormStrx3, dwFormAddrx3:
+		v.val = uint64(b.u24())
+	case dwFormData4, dwFormRef4, dwFormRefSup4, dwFormStrx4, dwFormAddrx4:
+		v.val = uint64(b.u32())
+	case dwFormData8, dwFormRef8, dwFormRefSig8, dwFormRefSup8:
+		v.val = b.u64()
+	case dwFormData16:
+		b.skip(16)
+	case dwFormSdata:
+		v.val = uint64(b.sleb())
+	case dwFormUdata, dwFormRefUdata, dwFormStrx, dwFormAddrx, dwFormLoclistx, dwFormRnglistx,
+		dwFormGNUAddrIndex, dwFormGNUStrIndex:
+		v.val = b.uleb()
+	case dwFormString:
+		v.str = b.cstr()
+	case dwFormStrp, dwFormLineStrp, dwFormSecOffset, dwFormStrpSup, dwFormGNURefAlt, dwFormGNUStrpAlt:
+		v.val = b.offset()
+	case dwFormRefAddr:
+		if cu.Version <= 2 {
+			v.val = b.addr(cu.addrSize)
+		} else {
+			v.val = b.offset()
+		}
+	case dwFormBlock1:
+		b.skip(int(b.u8()))
+	case dwFormBlock2:
+		b.skip(int(b.u16()))
+	case dwFormBlock4:
+		b.skip(int(b.u32()))
+	case dwFormBlock, dwFormExprloc:
+		b.skip(int(b.uleb()))
+	case dwFormFlagPresent:
+		v.val = 1
+	case dwFormImplicitConst:
+		v.val = uint64(implicit)
+	case dwFormIndirect:
+		// A form naming itself again would recurse once per byte of input
+		indirect := b.uleb()
+		if indirect == dwFormIndirect {
+			b.fail("DW_FORM_indirect naming DW_FORM_indirect")
+			return v
+		}
+		return d.readValue(b, cu, indirect, implicit)
+	default:
+		b.fail("unknown attribute form 0x%X", form)
+	}
+	return v
+}
+
+// str resolves a string class attribute value
+func (d *DWARF) str(cu *CompileUnit, v dwarfValue) string {
+	switch v.form {
+	case dwFormString:
+		return v.str
+	case dwFormStrp:
+		s, _ := readCStr(d.sections[".debug_str"], int(v.val))
+		return s
+	case dwFormLineStrp:
+		s, _ := readCStr(d.sections[".debug_line_str"], int(v.val))
+		return s
+	case dwFormStrx, dwFormStrx1, dwFormStrx2, dwFormStrx3, dwFormStrx4, dwFormGNUStrIndex:
+		b := d.buf(".debug_str_offsets", cu.strOffsetBase)
+		size := uint64(4)
+		if cu.dwarf64 {
+			size = 8
+		}
+		b.dwarf64 = cu.dwarf64
+		b.skip(int(v.val * size))
+		off := b.offset()
+		if b.err != nil {
+			return ""
+		}
+		s, _ := readCStr(d.sections[".debug_str"], int(off))
+		return s
+	}
+	return ""
+}
+
+// addr resolves an address class attribute value
+func (d *DWARF) addr(cu *CompileUnit, v dwarfValue) uint64 {
+	switch v.form {
+	case dwFormAddrx, dwFormAddrx1, dwFormAddrx2, dwFormAddrx3, dwFormAddrx4, dwFormGNUAddrIndex:
+		return d.addrIndex(cu, v.val)
+	}
+	return v.val
+}
+
+func (d *DWARF) addrIndex(cu *CompileUnit, index uint64) uint64 {
+	b := d.buf(".debug_addr", cu.addrBase+index*uint64(cu.addrSize))
+	return b.addr(cu.addrSize)
+}
+
+// ref resolves a reference class attribute value to a .debug_info offset
+func (d *DWARF) ref(cu *CompileUnit, v dwarfValue) uint64 {
+	switch v.form {
+	case dwFormRef1, dwFormRef2, dwFormRef4, dwFormRef8, dwFormRefUdata:
+		return cu.Offset + v.val
+	}
+	return v.val
+}
+
+// ranges returns the address ranges of a DIE from DW_AT_low_pc/DW_AT_high_pc
+// or DW_AT_ranges. base is the unit base address for range lists
+func (d *DWARF) ranges(cu *CompileUnit, attrs map[uint64]dwarfValue, base uint64) ([][2]uint64, error) {
+	ranges := make([][2]uint64, 0)
+	if low, ok := attrs[dwAtLowPC]; ok {
+		lowPC := d.addr(cu, low)
+		if high, ok := attrs[dwAtHighPC]; ok {
+			highPC := d.addr(cu, high)
+			switch high.form {
+			case dwFormAddr, dwFormAddrx, dwFormAddrx1, dwFormAddrx2, dwFormAddrx3, dwFormAddrx4, dwFormGNUAddrIndex:
+			default:
+				highPC = lowPC + high.val
+			}
+			if highPC > lowPC {
+				ranges = append(ranges, [2]uint64{lowPC, highPC})
+			}
+		}
+		if _, ok := attrs[dwAtRanges]; !ok {
+			return ranges, nil
+		}
+		if base == 0 {
+			base = lowPC
+		}
+	}
+	v, ok := attrs[dwAtRanges]
+	if !ok {
+		return ranges, nil
+	}
+	if cu.Version >= 5 {
+		return d.rangeList(cu, v, base)
+	}
+
+	b := d.buf(".debug_ranges", v.val)
+	for b.err == nil {
+		start := b.addr(cu.addrSize)
+		end := b.addr(cu.addrSize)
+		if b.err != nil {
+			return nil, b.err
+		}
+		if start == 0 && end == 0 {
+			break
+		}
+		if start == maxAddr(cu.addrSize) {
+			base = end
+			continue
+		}
+		if end > start {
+			ranges = append(ranges, [2]uint64{base + start, base + end})
+		}
+	}
+	return ranges, nil
+}
+
+func maxAddr(size uint8) uint64 {
+	if size >= 8 {
+		return ^uint64(0)
+	}
+	return 1<<(8*uint(size)) - 1
+}
+
+func (d *DWARF) rangeList(cu *CompileUnit, v dwarfValue, base uint64) ([][2]uint64, error) {
+	off := v.val
+	if v.form == dwFormRnglistx {
+		b := d.buf(".debug_rnglists", cu.rnglistsBase)
+		b.dwarf64 = cu.dwarf64
+		size := 4
+		if cu.dwarf64 {
+			size = 8
+		}
+		b.skip(int(v.val) * size)
+		off = cu.rnglistsBase + b.offset()
+		if b.err != nil {
+			return nil, b.err
+		}
+	}
+
+	ranges := make([][2]uint64, 0)
+	b := d.buf(".debug_rnglists", off)
+	for {
+		kind := b.u8()
+		var start, end uint64
+		switch kind {
+		case dwRLEEndOfList:
+			return ranges, b.err
+		case dwRLEBaseAddressx:
+			base = d.addrIndex(cu, b.uleb())
+			continue
+		case dwRLEStartxEndx:
+			start = d.addrIndex(cu, b.uleb())
+			end = d.addrIndex(cu, b.uleb())
+		case dwRLEStartxLength:
+			start = d.addrIndex(cu, b.uleb())
+			end = start + b.uleb()
+		case dwRLEOffsetPair:
+			start = base + b.uleb()
+			end = base + b.uleb()
+		case dwRLEBaseAddress:
+			base = b.addr(cu.addrSize)
+			continue
+		case dwRLEStartEnd:
+			start = b.addr(cu.addrSize)
+			end = b.addr(cu.addrSize)
+		case dwRLEStartLength:
+			start = b.addr(cu.addrSize)
+			end = start + b.uleb()
+		default:
+			b.fail("unknown range list entry 0x%X", kind)
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+		if end > start {
+			ranges = append(ranges, [2]uint64{start, end})
+		}
+	}
+}
+
+// resolveNames names functions through their DW_AT_specification and
+// DW_AT_abstract_origin chains, sorts them by address and indexes their
+// ranges for lookup
+func (d *DWARF) resolveNames() {
+	for _, fn := range d.Functions {
+		name := d.names[fn.offset]
+		for depth := 0; depth < 8 && (name.name == "" || name.linkage == ""); depth++ {
+			if name.reference == 0 {
+				break
+			}
+			ref, ok := d.names[name.reference]
+			if !ok {
+				break
+			}
+			if name.name == "" {
+				name.name = ref.name
+			}
+			if name.linkage == "" {
+				name.linkage = ref.linkage
+			}
+			name.reference = ref.reference
+		}
+		fn.Name = name.name
+		fn.Linkage = name.linkage
+	}
+	sort.SliceStable(d.Functions, func(i, j int) bool {
+		return d.Functions[i].Ranges[0][0] < d.Functions[j].Ranges[0][0]
+	})
+	for _, fn := range d.Functions {
+		for _, r := range fn.Ranges {
+			d.byAddr = append(d.byAddr, functionRange{start: r[0], end: r[1], fn: fn})
+		}
+	}
+	sort.SliceStable(d.byAddr, func(i, j int) bool {
+		return d.byAddr[i].start < d.byAddr[j].start
+	})
+}
+
+// FunctionForAddr returns the function whose code contains addr
+func (d *DWARF) FunctionForAddr(addr uint64) (*Function, error) {
+	i := sort.Search(len(d.byAddr), func(i int) bool {
+		return d.byAddr[i].start > addr
+	})
+	if i > 0 && addr < d.byAddr[i-1].end {
+		return d.byAddr[i-1].fn, nil
+	}
+	return nil, fmt.Errorf("elf: no function at 0x%X: %w", addr, ErrNoDWARFEntry)
+}
+
+// UnitForAddr returns the compile unit whose code contains addr
+func (d *DWARF) UnitForAddr(addr uint64) (*CompileUnit, error) {
+	for _, cu := range d.Units {
+		for _, r := range cu.Ranges {
+			if addr >= r[0] && addr < r[1] {
+				return cu, nil
+			}
+		}
+	}
+	// Units without ranges can still describe addr in their line table
+	for _, cu := range d.Units {
+		if len(cu.Ranges) != 0 {
+			continue
+		}
+		lines, err := d.LineTable(cu)
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := lineForAddr(lines, addr); ok {
+			return cu, nil
+		}
+	}
+	return nil, fmt.Errorf("elf: no compile unit at 0x%X: %w", addr, ErrNoDWARFEntry)
+}
+
+// LineForAddr returns the source line the code at addr was generated from
+func (d *DWARF) LineForAddr(addr uint64) (LineEntry, error) {
+	cu, err := d.UnitForAddr(addr)
+	if err != nil {
+		return LineEntry{}, err
+	}
+	lines, err := d.LineTable(cu)
+	if err != nil {
+		return LineEntry{}, err
+	}
+	if line, ok := lineForAddr(lines, addr); ok {
+		return line, nil
+	}
+	return LineEntry{}, fmt.Errorf("elf: no line at 0x%X: %w", addr, ErrNoDWARFEntry)
+}
+
+// joinPath resolves a file name against its directory
+func joinPath(dir, file string) string {
+	if dir == "" || path.IsAbs(file) {
+		return file
+	}
+	return path.Join(dir, file)
+}
@@ -0,0 +1,326 @@
+package elf
+
+import "sort"
+
+// DWARF line number content types, DWARF 5
+const (
+	dwLNCTPath           = 0x1
+	dwLNCTDirectoryIndex = 0x2
+)
+
+// DWARF standard line number opcodes
+const (
+	dwLNSCopy             = 0x1
+	dwLNSAdvancePC        = 0x2
+	dwLNSAdvanceLine      = 0x3
+	dwLNSSetFile          = 0x4
+	dwLNSSetColumn        = 0x5
+	dwLNSNegateStmt       = 0x6
+	dwLNSSetBasicBlock    = 0x7
+	dwLNSConstAddPC       = 0x8
+	dwLNSFixedAdvancePC   = 0x9
+	dwLNSSetPrologueEnd   = 0xa
+	dwLNSSetEpilogueBegin = 0xb
+	dwLNSSetISA           = 0xc
+)
+
+// DWARF extended line number opcodes
+const (
+	dwLNEEndSequence      = 0x1
+	dwLNESetAddress       = 0x2
+	dwLNEDefineFile       = 0x3
+	dwLNESetDiscriminator = 0x4
+)
+
+// LineEntry a row of the line number table
+type LineEntry struct {
+	Address     uint64 /* Address of the first instruction of the row */
+	File        string /* Source file, joined with its include directory */
+	Line        uint64 /* Source line, 0 when the code has no line */
+	Column      uint64 /* Source column, 0 for the whole line */
+	IsStmt      bool   /* Recommended breakpoint location */
+	EndSequence bool   /* First address past the end of a sequence, not an instruction */
+}
+
+type lineHeader struct {
+	version       uint16
+	addrSize      uint8
+	minInstLength uint8
+	defaultIsStmt bool
+	lineBase      int8
+	lineRange     uint8
+	opcodeBase    uint8
+	stdLengths    []uint8
+	dirs          []string
+	files         []string
+}
+
+// LineTable decodes the line number program of a compile unit. Rows are
+// ordered by address within each sequence, sequences are ordered by start address
+func (d *DWARF) LineTable(cu *CompileUnit) ([]LineEntry, error) {
+	if cu.linesDecoded {
+		return cu.lines, nil
+	}
+	if !cu.hasStmtList {
+		cu.linesDecoded = true
+		return nil, nil
+	}
+	lines, err := d.readLineProgram(cu)
+	if err != nil {
+		return nil, err
+	}
+	cu.lines = lines
+	cu.linesDecoded = true
+	return lines, nil
+}
+
+func (d *DWARF) readLineProgram(cu *CompileUnit) ([]LineEntry, error) {
+	b := d.buf(".debug_line", cu.stmtList)
+	end := b.unitLength()
+	h := lineHeader{addrSize: cu.addrSize}
+	h.version = b.u16()
+	if b.err == nil && (h.version < 2 || h.version > 5) {
+		return nil, formatErr(int64(cu.stmtList), ".debug_line", nil, "unsupported line table version %d", h.version)
+	}
+	if h.version >= 5 {
+		h.addrSize = b.u8()
+		b.u8() /* segment_selector_size */
+	}
+	headerLength := b.offset()
+	if b.err == nil && (b.off > end || headerLength > uint64(end-b.off)) {
+		return nil, formatErr(int64(cu.stmtList), ".debug_line", nil, "header length 0x%X past end of unit", headerLength)
+	}
+	program := b.off + int(headerLength)
+	h.minInstLength = b.u8()
+	if h.version >= 4 {
+		b.u8() /* maximum_operations_per_instruction, only used by VLIW */
+	}
+	h.defaultIsStmt = b.u8() != 0
+	h.lineBase = int8(b.u8())
+	h.lineRange = b.u8()
+	h.opcodeBase = b.u8()
+	for i := 1; i < int(h.opcodeBase); i++ {
+		h.stdLengths = append(h.stdLengths, b.u8())
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if h.lineRange == 0 {
+		return nil, formatErr(int64(cu.stmtList), ".debug_line", nil, "line_range is zero")
+	}
+
+	if h.version >= 5 {
+		if err := d.readEntryFormats(b, cu, &h, end); err != nil {
+			return nil, err
+		}
+	} else {
+		// Directory 0 and file 0 are the compilation directory and primary
+		// source file, the tables proper are 1 based
+		h.dirs = append(h.dirs, cu.CompDir)
+		for {
+			dir := b.cstr()
+			if dir == "" || b.err != nil {
+				break
+			}
+			h.dirs = append(h.dirs, joinPath(cu.CompDir, dir))
+		}
+		h.files = append(h.files, joinPath(cu.CompDir, cu.Name))
+		for {
+			name := b.cstr()
+			if name == "" || b.err != nil {
+				break
+			}
+			h.files = append(h.files, h.fileName(name, b.uleb()))
+			b.uleb() /* modification time */
+			b.uleb() /* length */
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	b.off = program
+	lines, err := h.run(b, end)
+	if err != nil {
+		return nil, err
+	}
+	return lines, nil
+}
+
+func (h *lineHeader) fileName(name string, dir uint64) string {
+	if dir < uint64(len(h.dirs)) {
+		return joinPath(h.dirs[dir], name)
+	}
+	return name
+}
+
+// readEntryFormats reads the self describing directory and file tables of
+// DWARF 5 from a unit ending at end
+func (d *DWARF) readEntryFormats(b *dwarfBuf, cu *CompileUnit, h *lineHeader, end int) error {
+	// The line table has its own address size, forms are otherwise the same
+	// as in .debug_info
+	unit := &CompileUnit{Version: h.version, addrSize: h.addrSize, dwarf64: b.dwarf64, strOffsetBase: cu.strOffsetBase}
+	readTable := func() [][2]dwarfValue {
+		formats := make([][2]uint64, b.u8())
+		for i := range formats {
+			formats[i][0] = b.uleb()
+			formats[i][1] = b.uleb()
+		}
+		count := b.uleb()
+		// Every entry takes at least a byte, unless no form reads any
+		reads := false
+		for _, format := range formats {
+			if format[1] != dwFormFlagPresent && format[1] != dwFormImplicitConst {
+				reads = true
+			}
+		}
+		if b.err == nil && (b.off > end || count > uint64(end-b.off) || (count > 0 && !reads)) {
+			b.fail("0x%X directory or file entries past end of unit", count)
+			return nil
+		}
+		var entries [][2]dwarfValue
+		for i := uint64(0); i < count && b.err == nil; i++ {
+			var entry [2]dwarfValue /* path, directory index */
+			for _, format := range formats {
+				v := d.readValue(b, unit, format[1], 0)
+				switch format[0] {
+				case dwLNCTPath:
+					entry[0] = v
+				case dwLNCTDirectoryIndex:
+					entry[1] = v
+				}
+			}
+			entries = append(entries, entry)
+		}
+		return entries
+	}
+
+	for i, entry := range readTable() {
+		dir := d.str(cu, entry[0])
+		if i > 0 {
+			dir = joinPath(h.dirs[0], dir)
+		}
+		h.dirs = append(h.dirs, dir)
+	}
+	for _, entry := range readTable() {
+		h.files = append(h.files, h.fileName(d.str(cu, entry[0]), entry[1].val))
+	}
+	return b.err
+}
+
+// run executes the line number program up to end
+func (h *lineHeader) run(b *dwarfBuf, end int) ([]LineEntry, error) {
+	var lines []LineEntry
+	var address, file, line, column uint64
+	var isStmt bool
+	reset := func() {
+		address, file, line, column = 0, 1, 1, 0
+		isStmt = h.defaultIsStmt
+	}
+	emit := func(endSequence bool) {
+		entry := LineEntry{Address: address, Line: line, Column: column, IsStmt: isStmt, EndSequence: endSequence}
+		if file < uint64(len(h.files)) {
+			entry.File = h.files[file]
+		}
+		lines = append(lines, entry)
+	}
+	reset()
+
+	for b.off < end && b.err == nil {
+		op := b.u8()
+		if op >= h.opcodeBase {
+			adjusted := op - h.opcodeBase
+			address += uint64(adjusted/h.lineRange) * uint64(h.minInstLength)
+			line += uint64(int64(h.lineBase) + int64(adjusted%h.lineRange))
+			emit(false)
+			continue
+		}
+		switch op {
+		case 0:
+			length := b.uleb()
+			if b.err == nil && (b.off > end || length > uint64(end-b.off)) {
+				b.fail("extended opcode length 0x%X past end of unit", length)
+				continue
+			}
+			next := b.off + int(length)
+			if length == 0 {
+				continue
+			}
+			switch b.u8() {
+			case dwLNEEndSequence:
+				emit(true)
+				reset()
+			case dwLNESetAddress:
+				address = b.addr(uint8(length - 1))
+			case dwLNEDefineFile:
+				name := b.cstr()
+				h.files = append(h.files, h.fileName(name, b.uleb()))
+			case dwLNESetDiscriminator:
+			}
+			if next > end {
+				b.fail("extended opcode length 0x%X past end of unit", length)
+			} else {
+				b.off = next
+			}
+		case dwLNSCopy:
+			emit(false)
+		case dwLNSAdvancePC:
+			address += b.uleb() * uint64(h.minInstLength)
+		case dwLNSAdvanceLine:
+			line += uint64(b.sleb())
+		case dwLNSSetFile:
+			file = b.uleb()
+		case dwLNSSetColumn:
+			column = b.uleb()
+		case dwLNSNegateStmt:
+			isStmt = !isStmt
+		case dwLNSSetBasicBlock, dwLNSSetPrologueEnd, dwLNSSetEpilogueBegin:
+		case dwLNSConstAddPC:
+			address += uint64((255-h.opcodeBase)/h.lineRange) * uint64(h.minInstLength)
+		case dwLNSFixedAdvancePC:
+			address += uint64(b.u16())
+		case dwLNSSetISA:
+			b.uleb()
+		default:
+			// Opcodes newer than the decoder, skip their ULEB128 operands
+			for i := uint8(0); i < h.stdLengths[op-1]; i++ {
+				b.uleb()
+			}
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	// Order sequences by start address, keeping each sequence's rows together
+	var starts []int
+	for i := range lines {
+		if i == 0 || lines[i-1].EndSequence {
+			starts = append(starts, i)
+		}
+	}
+	sort.SliceStable(starts, func(i, j int) bool {
+		return lines[starts[i]].Address < lines[starts[j]].Address
+	})
+	sorted := make([]LineEntry, 0, len(lines))
+	for _, start := range starts {
+		for i := start; i < len(lines); i++ {
+			sorted = append(sorted, lines[i])
+			if lines[i].EndSequence {
+				break
+			}
+		}
+	}
+	return sorted, nil
+}
+
+// lineForAddr finds the row covering addr: the last row at or below addr
+// whose sequence has not ended
+func lineForAddr(lines []LineEntry, addr uint64) (LineEntry, bool) {
+	i := sort.Search(len(lines), func(i int) bool {
+		return lines[i].Address > addr
+	})
+	if i == 0 || lines[i-1].EndSequence {
+		return LineEntry{}, false
+	}
+	return lines[i-1], true
+}
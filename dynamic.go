@@ -0,0 +1,427 @@
+package elf
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DTagDecode map of d_tag to friendly string
+var DTagDecode = map[uint64]string{
+	0:          "DT_NULL",            /* Marks end of dynamic section */
+	1:          "DT_NEEDED",          /* Name of needed library */
+	2:          "DT_PLTRELSZ",        /* Size in bytes of PLT relocs */
+	3:          "DT_PLTGOT",          /* Processor defined value */
+	4:          "DT_HASH",            /* Address of symbol hash table */
+	5:          "DT_STRTAB",          /* Address of string table */
+	6:          "DT_SYMTAB",          /* Address of symbol table */
+	7:          "DT_RELA",            /* Address of Rela relocs */
+	8:          "DT_RELASZ",          /* Total size of Rela relocs */
+	9:          "DT_RELAENT",         /* Size of one Rela reloc */
+	10:         "DT_STRSZ",           /* Size of string table */
+	11:         "DT_SYMENT",          /* Size of one symbol table entry */
+	12:         "DT_INIT",            /* Address of init function */
+	13:         "DT_FINI",            /* Address of termination function */
+	14:         "DT_SONAME",          /* Name of shared object */
+	15:         "DT_RPATH",           /* Library search path (deprecated) */
+	16:         "DT_SYMBOLIC",        /* Start symbol search here */
+	17:         "DT_REL",             /* Address of Rel relocs */
+	18:         "DT_RELSZ",           /* Total size of Rel relocs */
+	19:         "DT_RELENT",          /* Size of one Rel reloc */
+	20:         "DT_PLTREL",          /* Type of reloc in PLT */
+	21:         "DT_DEBUG",           /* For debugging; unspecified */
+	22:         "DT_TEXTREL",         /* Reloc might modify .text */
+	23:         "DT_JMPREL",          /* Address of PLT relocs */
+	24:         "DT_BIND_NOW",        /* Process relocations of object */
+	25:         "DT_INIT_ARRAY",      /* Array with addresses of init fct */
+	26:         "DT_FINI_ARRAY",      /* Array with addresses of fini fct */
+	27:         "DT_INIT_ARRAYSZ",    /* Size in bytes of DT_INIT_ARRAY */
+	28:         "DT_FINI_ARRAYSZ",    /* Size in bytes of DT_FINI_ARRAY */
+	29:         "DT_RUNPATH",         /* Library search path */
+	30:         "DT_FLAGS",           /* Flags for the object being loaded */
+	32:         "DT_PREINIT_ARRAY",   /* Array with addresses of preinit fct */
+	33:         "DT_PREINIT_ARRAYSZ", /* Size in bytes of DT_PREINIT_ARRAY */
+	34:         "DT_SYMTAB_SHNDX",    /* Address of SYMTAB_SHNDX section */
+	35:         "DT_RELRSZ",          /* Total size of RELR relative relocations */
+	36:         "DT_RELR",            /* Address of RELR relative relocations */
+	37:         "DT_RELRENT",         /* Size of one RELR relative relocation */
+	0x6000000d: "DT_LOOS",            /* Start of OS-specific */
+	0x6000000f: "DT_ANDROID_REL",     /* Address of APS2 packed relocations */
+	0x60000010: "DT_ANDROID_RELSZ",   /* Total size of APS2 packed relocations */
+	0x60000011: "DT_ANDROID_RELA",    /* Address of APS2 packed relocations with addends */
+	0x60000012: "DT_ANDROID_RELASZ",  /* Total size of APS2 packed relocations with addends */
+	0x6fffe000: "DT_ANDROID_RELR",    /* Address of RELR relative relocations, pre-standard */
+	0x6fffe001: "DT_ANDROID_RELRSZ",  /* Total size of DT_ANDROID_RELR */
+	0x6fffe003: "DT_ANDROID_RELRENT", /* Size of one DT_ANDROID_RELR entry */
+	0x6ffff000: "DT_HIOS",            /* End of OS-specific */
+	0x6ffffdf5: "DT_GNU_PRELINKED",   /* Prelinking timestamp */
+	0x6ffffdf6: "DT_GNU_CONFLICTSZ",  /* Size of conflict section */
+	0x6ffffdf7: "DT_GNU_LIBLISTSZ",   /* Size of library list */
+	0x6ffffdf8: "DT_CHECKSUM",        /* Checksum of the object */
+	0x6ffffdf9: "DT_PLTPADSZ",        /* Size of PLT padding */
+	0x6ffffdfa: "DT_MOVEENT",         /* Size of one move table entry */
+	0x6ffffdfb: "DT_MOVESZ",          /* Size of move table */
+	0x6ffffdfc: "DT_FEATURE_1",       /* Feature selection (DTF_*) */
+	0x6ffffdfd: "DT_POSFLAG_1",       /* Flags for DT_* entries, effecting the following DT_* entry */
+	0x6ffffdfe: "DT_SYMINSZ",         /* Size of syminfo table (in bytes) */
+	0x6ffffdff: "DT_SYMINENT",        /* Entry size of syminfo */
+	0x6ffffef5: "DT_GNU_HASH",        /* GNU-style hash table */
+	0x6ffffef6: "DT_TLSDESC_PLT",     /* Location of PLT entry for TLS descriptor resolver calls */
+	0x6ffffef7: "DT_TLSDESC_GOT",     /* Location of GOT entry used by TLS descriptor resolver PLT entry */
+	0x6ffffef8: "DT_GNU_CONFLICT",    /* Start of conflict section */
+	0x6ffffef9: "DT_GNU_LIBLIST",     /* Library list */
+	0x6ffffefa: "DT_CONFIG",          /* Configuration information */
+	0x6ffffefb: "DT_DEPAUDIT",        /* Dependency auditing */
+	0x6ffffefc: "DT_AUDIT",           /* Object auditing */
+	0x6ffffefd: "DT_PLTPAD",          /* PLT padding */
+	0x6ffffefe: "DT_MOVETAB",         /* Move table */
+	0x6ffffeff: "DT_SYMINFO",         /* Syminfo table */
+	0x6ffffff0: "DT_VERSYM",          /* Address of .gnu.version */
+	0x6ffffff9: "DT_RELACOUNT",       /* Number of relative Rela relocs */
+	0x6ffffffa: "DT_RELCOUNT",        /* Number of relative Rel relocs */
+	0x6ffffffb: "DT_FLAGS_1",         /* State flags, see DF_1_* */
+	0x6ffffffc: "DT_VERDEF",          /* Address of version definition table */
+	0x6ffffffd: "DT_VERDEFNUM",       /* Number of version definitions */
+	0x6ffffffe: "DT_VERNEED",         /* Address of table with needed versions */
+	0x6fffffff: "DT_VERNEEDNUM",      /* Number of needed versions */
+	0x70000000: "DT_LOPROC",          /* Start of processor-specific */
+	0x7ffffffd: "DT_AUXILIARY",       /* Shared object to load before self */
+	0x7fffffff: "DT_FILTER",          /* Shared object to get values from */
+}
+
+// DTagEncode map of friendly string to d_tag
+var DTagEncode = map[string]uint64{
+	"DT_NULL":            0,
+	"DT_NEEDED":          1,
+	"DT_PLTRELSZ":        2,
+	"DT_PLTGOT":          3,
+	"DT_HASH":            4,
+	"DT_STRTAB":          5,
+	"DT_SYMTAB":          6,
+	"DT_RELA":            7,
+	"DT_RELASZ":          8,
+	"DT_RELAENT":         9,
+	"DT_STRSZ":           10,
+	"DT_SYMENT":          11,
+	"DT_INIT":            12,
+	"DT_FINI":            13,
+	"DT_SONAME":          14,
+	"DT_RPATH":           15,
+	"DT_SYMBOLIC":        16,
+	"DT_REL":             17,
+	"DT_RELSZ":           18,
+	"DT_RELENT":          19,
+	"DT_PLTREL":          20,
+	"DT_DEBUG":           21,
+	"DT_TEXTREL":         22,
+	"DT_JMPREL":          23,
+	"DT_BIND_NOW":        24,
+	"DT_INIT_ARRAY":      25,
+	"DT_FINI_ARRAY":      26,
+	"DT_INIT_ARRAYSZ":    27,
+	"DT_FINI_ARRAYSZ":    28,
+	"DT_RUNPATH":         29,
+	"DT_FLAGS":           30,
+	"DT_PREINIT_ARRAY":   32,
+	"DT_PREINIT_ARRAYSZ": 33,
+	"DT_SYMTAB_SHNDX":    34,
+	"DT_RELRSZ":          35,
+	"DT_RELR":            36,
+	"DT_RELRENT":         37,
+	"DT_LOOS":            0x6000000d,
+	"DT_ANDROID_REL":     0x6000000f,
+	"DT_ANDROID_RELSZ":   0x60000010,
+	"DT_ANDROID_RELA":    0x60000011,
+	"DT_ANDROID_RELASZ":  0x60000012,
+	"DT_ANDROID_RELR":    0x6fffe000,
+	"DT_ANDROID_RELRSZ":  0x6fffe001,
+	"DT_ANDROID_RELRENT": 0x6fffe003,
+	"DT_HIOS":            0x6ffff000,
+	"DT_GNU_PRELINKED":   0x6ffffdf5,
+	"DT_GNU_CONFLICTSZ":  0x6ffffdf6,
+	"DT_GNU_LIBLISTSZ":   0x6ffffdf7,
+	"DT_CHECKSUM":        0x6ffffdf8,
+	"DT_PLTPADSZ":        0x6ffffdf9,
+	"DT_MOVEENT":         0x6ffffdfa,
+	"DT_MOVESZ":          0x6ffffdfb,
+	"DT_FEATURE_1":       0x6ffffdfc,
+	"DT_POSFLAG_1":       0x6ffffdfd,
+	"DT_SYMINSZ":         0x6ffffdfe,
+	"DT_SYMINENT":        0x6ffffdff,
+	"DT_GNU_HASH":        0x6ffffef5,
+	"DT_TLSDESC_PLT":     0x6ffffef6,
+	"DT_TLSDESC_GOT":     0x6ffffef7,
+	"DT_GNU_CONFLICT":    0x6ffffef8,
+	"DT_GNU_LIBLIST":     0x6ffffef9,
+	"DT_CONFIG":          0x6ffffefa,
+	"DT_DEPAUDIT":        0x6ffffefb,
+	"DT_AUDIT":           0x6ffffefc,
+	"DT_PLTPAD":          0x6ffffefd,
+	"DT_MOVETAB":         0x6ffffefe,
+	"DT_SYMINFO":         0x6ffffeff,
+	"DT_VERSYM":          0x6ffffff0,
+	"DT_RELACOUNT":       0x6ffffff9,
+	"DT_RELCOUNT":        0x6ffffffa,
+	"DT_FLAGS_1":         0x6ffffffb,
+	"DT_VERDEF":          0x6ffffffc,
+	"DT_VERDEFNUM":       0x6ffffffd,
+	"DT_VERNEED":         0x6ffffffe,
+	"DT_VERNEEDNUM":      0x6fffffff,
+	"DT_LOPROC":          0x70000000,
+	"DT_AUXILIARY":       0x7ffffffd,
+	"DT_FILTER":          0x7fffffff,
+}
+
+// DFFlagsDecode map of DT_FLAGS bits to friendly string
+var DFFlagsDecode = map[uint64]string{
+	0x01: "DF_ORIGIN",     /* Object may use DF_ORIGIN */
+	0x02: "DF_SYMBOLIC",   /* Symbol resolutions starts here */
+	0x04: "DF_TEXTREL",    /* Object contains text relocations */
+	0x08: "DF_BIND_NOW",   /* No lazy binding for this object */
+	0x10: "DF_STATIC_TLS", /* Module uses the static TLS model */
+}
+
+// DF1FlagsDecode map of DT_FLAGS_1 bits to friendly string
+var DF1FlagsDecode = map[uint64]string{
+	0x00000001: "DF_1_NOW",        /* Set RTLD_NOW for this object */
+	0x00000002: "DF_1_GLOBAL",     /* Set RTLD_GLOBAL for this object */
+	0x00000004: "DF_1_GROUP",      /* Set RTLD_GROUP for this object */
+	0x00000008: "DF_1_NODELETE",   /* Set RTLD_NODELETE for this object */
+	0x00000010: "DF_1_LOADFLTR",   /* Trigger filtee loading at runtime */
+	0x00000020: "DF_1_INITFIRST",  /* Set RTLD_INITFIRST for this object */
+	0x00000040: "DF_1_NOOPEN",     /* Set RTLD_NOOPEN for this object */
+	0x00000080: "DF_1_ORIGIN",     /* $ORIGIN must be handled */
+	0x00000100: "DF_1_DIRECT",     /* Direct binding enabled */
+	0x00000200: "DF_1_TRANS",      /* Unused */
+	0x00000400: "DF_1_INTERPOSE",  /* Object is used to interpose */
+	0x00000800: "DF_1_NODEFLIB",   /* Ignore default lib search path */
+	0x00001000: "DF_1_NODUMP",     /* Object can't be dldump'ed */
+	0x00002000: "DF_1_CONFALT",    /* Configuration alternative created */
+	0x00004000: "DF_1_ENDFILTEE",  /* Filtee terminates filters search */
+	0x00008000: "DF_1_DISPRELDNE", /* Disp reloc applied at build time */
+	0x00010000: "DF_1_DISPRELPND", /* Disp reloc applied at run-time */
+	0x00020000: "DF_1_NODIRECT",   /* Object has no-direct binding */
+	0x00040000: "DF_1_IGNMULDEF",  /* Unused */
+	0x00080000: "DF_1_NOKSYMS",    /* Unused */
+	0x00100000: "DF_1_NOHDR",      /* Unused */
+	0x00200000: "DF_1_EDITED",     /* Object is modified after built */
+	0x00400000: "DF_1_NORELOC",    /* Unused */
+	0x00800000: "DF_1_SYMINTPOSE", /* Object has individual interposers */
+	0x01000000: "DF_1_GLOBAUDIT",  /* Global auditing required */
+	0x02000000: "DF_1_SINGLETON",  /* Singleton symbols are used */
+	0x04000000: "DF_1_STUB",       /* Object is a stub */
+	0x08000000: "DF_1_PIE",        /* Object is a position independent executable */
+}
+
+// dtagStrings are the tags whose d_val is an offset into the DT_STRTAB string table
+var dtagStrings = map[uint64]bool{
+	1:          true, /* DT_NEEDED */
+	14:         true, /* DT_SONAME */
+	15:         true, /* DT_RPATH */
+	29:         true, /* DT_RUNPATH */
+	0x6ffffefa: true, /* DT_CONFIG */
+	0x6ffffefb: true, /* DT_DEPAUDIT */
+	0x6ffffefc: true, /* DT_AUDIT */
+	0x7ffffffd: true, /* DT_AUXILIARY */
+	0x7fffffff: true, /* DT_FILTER */
+}
+
+// DynamicEntry entry of the dynamic section, widened to the 64 bit layout
+type DynamicEntry struct {
+	DTag uint64 /* Identifies the type of the entry */
+	DVal uint64 /* Integer value or address, depending on DTag */
+	Tag  string /* Friendly name of DTag */
+	Str  string /* String DVal refers to, for DT_NEEDED and friends */
+}
+
+// DynamicTable the decoded contents of the dynamic section
+type DynamicTable []DynamicEntry
+
+// Dynamic decodes the SHT_DYNAMIC section, or the PT_DYNAMIC segment when the
+// file has no section headers
+func (e ELF64) Dynamic() (DynamicTable, error) {
+	return readDynamic(e)
+}
+
+// Dynamic decodes the SHT_DYNAMIC section, or the PT_DYNAMIC segment when the
+// file has no section headers
+func (e ELF32) Dynamic() (DynamicTable, error) {
+	return readDynamic(e)
+}
+
+func readDynamic(f File) (DynamicTable, error) {
+	sHead := f.SectionTable()
+	sections := f.SectionData()
+	var data []byte
+	var base int64
+	link := uint32(0)
+	if index := findSection(sHead, SHTypeEncode["SHT_DYNAMIC"]); index >= 0 {
+		data = sections[index].Data
+		base = int64(sHead[index].SHOffset)
+		link = sHead[index].SHLink
+	} else {
+		// Fall back to the segment for files without section headers
+		found := false
+		for _, ph := range f.Segments() {
+			if ph.PType == PTypeEncode["PT_DYNAMIC"] {
+				data = ph.Data
+				base = int64(ph.POffset)
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, ErrNoDynamic
+		}
+	}
+
+	u32 := readu32Func(f.Header().EIDATA)
+	u64 := readu64Func(f.Header().EIDATA)
+	entSize := 16
+	if f.Class() == CLASS32BIT {
+		entSize = 8
+	}
+	table := make(DynamicTable, 0, len(data)/entSize)
+	for off := 0; off+entSize <= len(data); off += entSize {
+		var d DynamicEntry
+		if f.Class() == CLASS32BIT {
+			d.DTag = uint64(u32(data, off))
+			d.DVal = uint64(u32(data, off+4))
+		} else {
+			d.DTag = u64(data, off)
+			d.DVal = u64(data, off+8)
+		}
+		d.Tag = DTagDecode[d.DTag]
+		if d.Tag == "" {
+			d.Tag = fmt.Sprintf("0x%X", d.DTag)
+		}
+		table = append(table, d)
+		if d.DTag == DTagEncode["DT_NULL"] {
+			break
+		}
+	}
+
+	strtab, err := dynamicStrtab(f, table, link)
+	if err != nil {
+		return nil, err
+	}
+	for i, d := range table {
+		if dtagStrings[d.DTag] {
+			str, ok := readCStr(strtab, int(d.DVal))
+			if !ok {
+				return nil, formatErr(base+int64(i*entSize), "dynamic entry", nil, "%s string 0x%X out of range", d.Tag, d.DVal)
+			}
+			table[i].Str = str
+		}
+	}
+	return table, nil
+}
+
+// dynamicStrtab finds the string table the dynamic section refers to, by the
+// address in DT_STRTAB, the dynamic section's sh_link, or failing that by
+// reading DT_STRSZ bytes at DT_STRTAB from the loaded segments
+func dynamicStrtab(f File, table DynamicTable, link uint32) ([]byte, error) {
+	sHead := f.SectionTable()
+	sections := f.SectionData()
+	addr, ok := table.value("DT_STRTAB")
+	if ok {
+		for i, h := range sHead {
+			if h.SHAddr == addr && h.SHType != SHTypeEncode["SHT_NOBITS"] {
+				return sections[i].Data, nil
+			}
+		}
+	}
+	if link != 0 && int(link) < len(sections) {
+		return sections[link].Data, nil
+	}
+	if !ok {
+		return nil, nil
+	}
+	size, _ := table.value("DT_STRSZ")
+	space := newAddressSpace(f.Segments(), f.Class(), f.Header().EIDATA)
+	if err := space.checkMapped(addr, size); err != nil {
+		return nil, err
+	}
+	strtab := make([]byte, size)
+	if _, err := space.ReadAt(strtab, int64(addr)); err != nil {
+		return nil, err
+	}
+	return strtab, nil
+}
+
+func (t DynamicTable) value(tag string) (uint64, bool) {
+	for _, d := range t {
+		if d.DTag == DTagEncode[tag] {
+			return d.DVal, true
+		}
+	}
+	return 0, false
+}
+
+func (t DynamicTable) strings(tag string) []string {
+	strs := make([]string, 0)
+	for _, d := range t {
+		if d.DTag == DTagEncode[tag] {
+			strs = append(strs, d.Str)
+		}
+	}
+	return strs
+}
+
+// Needed returns the libraries named by DT_NEEDED entries
+func (t DynamicTable) Needed() []string {
+	return t.strings("DT_NEEDED")
+}
+
+// Soname returns the DT_SONAME of the object, or "" if it has none
+func (t DynamicTable) Soname() string {
+	names := t.strings("DT_SONAME")
+	if len(names) == 0 {
+		return ""
+	}
+	return names[0]
+}
+
+// Rpath returns the colon separated directories of DT_RPATH
+func (t DynamicTable) Rpath() []string {
+	return splitPaths(t.strings("DT_RPATH"))
+}
+
+// Runpath returns the colon separated directories of DT_RUNPATH
+func (t DynamicTable) Runpath() []string {
+	return splitPaths(t.strings("DT_RUNPATH"))
+}
+
+// Flags returns the friendly names of the bits set in DT_FLAGS
+func (t DynamicTable) Flags() []string {
+	val, _ := t.value("DT_FLAGS")
+	return decodeBits(val, DFFlagsDecode)
+}
+
+// Flags1 returns the friendly names of the bits set in DT_FLAGS_1
+func (t DynamicTable) Flags1() []string {
+	val, _ := t.value("DT_FLAGS_1")
+	return decodeBits(val, DF1FlagsDecode)
+}
+
+func splitPaths(entries []string) []string {
+	paths := make([]string, 0)
+	for _, entry := range entries {
+		paths = append(paths, strings.Split(entry, ":")...)
+	}
+	return paths
+}
+
+func decodeBits(val uint64, names map[uint64]string) []string {
+	flags := make([]string, 0)
+	for i := 0; i < 64; i++ {
+		var bitmask uint64 = 1 << i
+		if val&bitmask != 0 {
+			flagStr := names[bitmask]
+			if flagStr == "" {
+				flagStr = fmt.Sprintf("0x%X", bitmask)
+			}
+			flags = append(flags, flagStr)
+		}
+	}
+	return flags
+}
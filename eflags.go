@@ -0,0 +1,130 @@
+package elf
+
+import "fmt"
+
+// EFARMDecode map of ARM e_flags bits to friendly string
+var EFARMDecode = map[uint64]string{
+	0x00000200: "EF_ARM_ABI_FLOAT_SOFT", /* Soft float calling convention */
+	0x00000400: "EF_ARM_ABI_FLOAT_HARD", /* Hard float calling convention (VFP registers) */
+	0x00400000: "EF_ARM_LE8",            /* Little endian code in a BE8 image */
+	0x00800000: "EF_ARM_BE8",            /* Byte invariant big endian image */
+}
+
+// EFMIPSDecode map of MIPS e_flags bits to friendly string
+var EFMIPSDecode = map[uint64]string{
+	0x00000001: "EF_MIPS_NOREORDER", /* Contains .noreorder code */
+	0x00000002: "EF_MIPS_PIC",       /* Position independent code */
+	0x00000004: "EF_MIPS_CPIC",      /* Calls PIC code through the GOT */
+	0x00000008: "EF_MIPS_XGOT",      /* Large GOT */
+	0x00000020: "EF_MIPS_ABI2",      /* N32 ABI */
+	0x00000100: "EF_MIPS_32BITMODE", /* 64 bit ISA used in 32 bit mode */
+	0x00000200: "EF_MIPS_FP64",      /* 64 bit floating point registers with a 32 bit ABI */
+	0x00000400: "EF_MIPS_NAN2008",   /* IEEE 754-2008 NaN encoding */
+}
+
+// EFMIPSABIDecode map of the EF_MIPS_ABI field (mask 0x0000F000) to friendly string
+var EFMIPSABIDecode = map[uint32]string{
+	0x00001000: "EF_MIPS_ABI_O32",    /* Original 32 bit ABI */
+	0x00002000: "EF_MIPS_ABI_O64",    /* O32 extended to 64 bit registers */
+	0x00003000: "EF_MIPS_ABI_EABI32", /* Embedded ABI, 32 bit */
+	0x00004000: "EF_MIPS_ABI_EABI64", /* Embedded ABI, 64 bit */
+}
+
+// EFMIPSArchDecode map of the EF_MIPS_ARCH field (mask 0xF0000000) to friendly string
+var EFMIPSArchDecode = map[uint32]string{
+	0x00000000: "EF_MIPS_ARCH_1",
+	0x10000000: "EF_MIPS_ARCH_2",
+	0x20000000: "EF_MIPS_ARCH_3",
+	0x30000000: "EF_MIPS_ARCH_4",
+	0x40000000: "EF_MIPS_ARCH_5",
+	0x50000000: "EF_MIPS_ARCH_32",
+	0x60000000: "EF_MIPS_ARCH_64",
+	0x70000000: "EF_MIPS_ARCH_32R2",
+	0x80000000: "EF_MIPS_ARCH_64R2",
+	0x90000000: "EF_MIPS_ARCH_32R6",
+	0xA0000000: "EF_MIPS_ARCH_64R6",
+}
+
+// EFRISCVDecode map of RISC-V e_flags bits to friendly string
+var EFRISCVDecode = map[uint64]string{
+	0x00000001: "EF_RISCV_RVC", /* Compressed instructions */
+	0x00000008: "EF_RISCV_RVE", /* RV32E, 16 integer registers */
+	0x00000010: "EF_RISCV_TSO", /* Total store ordering memory model */
+}
+
+// EFRISCVFloatABIDecode map of the EF_RISCV_FLOAT_ABI field (mask 0x6) to friendly string
+var EFRISCVFloatABIDecode = map[uint32]string{
+	0x0: "EF_RISCV_FLOAT_ABI_SOFT",
+	0x2: "EF_RISCV_FLOAT_ABI_SINGLE",
+	0x4: "EF_RISCV_FLOAT_ABI_DOUBLE",
+	0x6: "EF_RISCV_FLOAT_ABI_QUAD",
+}
+
+// EFPPC64ABIDecode map of the EF_PPC64_ABI field (mask 0x3) to friendly string
+var EFPPC64ABIDecode = map[uint32]string{
+	0x1: "EF_PPC64_ABI_V1", /* ELFv1, function descriptors */
+	0x2: "EF_PPC64_ABI_V2", /* ELFv2, global and local entry points */
+}
+
+// eflagsDecoders split e_flags into friendly names, keyed by EMachine
+var eflagsDecoders = map[uint16]func(flags uint32) []string{
+	0x08: mipsEFlags,
+	0x15: ppc64EFlags,
+	0x28: armEFlags,
+	0xF3: riscvEFlags,
+}
+
+// EFlagsNames decodes the e_flags of a file for the given EMachine. Bits and
+// field values without a name are rendered in hex, as are the flags of
+// machines without a decoder
+func EFlagsNames(machine uint16, flags uint32) []string {
+	if decoder, ok := eflagsDecoders[machine]; ok {
+		return decoder(flags)
+	}
+	if flags == 0 {
+		return []string{}
+	}
+	return []string{fmt.Sprintf("0x%X", flags)}
+}
+
+// armEFlags decodes the EABI version (top byte), the float ABI and BE8/LE8
+func armEFlags(flags uint32) []string {
+	names := []string{}
+	if ver := flags >> 24; ver != 0 {
+		names = append(names, fmt.Sprintf("EF_ARM_EABI_VER%d", ver))
+	} else {
+		names = append(names, "EF_ARM_EABI_UNKNOWN")
+	}
+	return append(names, decodeBits(uint64(flags&0x00FFFFFF), EFARMDecode)...)
+}
+
+// mipsEFlags decodes the ISA level, the ABI and the low bits. The machine
+// variant (0x00FF0000) and ASE (0x0F000000) fields are left in hex
+func mipsEFlags(flags uint32) []string {
+	names := []string{decodeOrHex(EFMIPSArchDecode[flags&0xF0000000], uint64(flags&0xF0000000))}
+	if abi := flags & 0x0000F000; abi != 0 {
+		names = append(names, decodeOrHex(EFMIPSABIDecode[abi], uint64(abi)))
+	}
+	names = append(names, decodeBits(uint64(flags&0x00000FFF), EFMIPSDecode)...)
+	for _, field := range []uint32{flags & 0x00FF0000, flags & 0x0F000000} {
+		if field != 0 {
+			names = append(names, fmt.Sprintf("0x%X", field))
+		}
+	}
+	return names
+}
+
+// riscvEFlags decodes the float ABI and the RVC, RVE and TSO bits
+func riscvEFlags(flags uint32) []string {
+	names := []string{EFRISCVFloatABIDecode[flags&0x6]}
+	return append(names, decodeBits(uint64(flags&^0x6), EFRISCVDecode)...)
+}
+
+// ppc64EFlags decodes the ELFv1/ELFv2 ABI field, 0 meaning unspecified
+func ppc64EFlags(flags uint32) []string {
+	names := []string{}
+	if abi := flags & 0x3; abi != 0 {
+		names = append(names, decodeOrHex(EFPPC64ABIDecode[abi], uint64(abi)))
+	}
+	return append(names, decodeBits(uint64(flags&^0x3), nil)...)
+}
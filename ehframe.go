@@ -0,0 +1,583 @@
+package elf
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// DWEHPEDecode maps the format (low nibble) of a DW_EH_PE_ pointer encoding to friendly name
+var DWEHPEDecode = map[uint8]string{
+	0x00: "DW_EH_PE_absptr",  /* Pointer sized */
+	0x01: "DW_EH_PE_uleb128", /* Unsigned LEB128 */
+	0x02: "DW_EH_PE_udata2",  /* Unsigned 2 bytes */
+	0x03: "DW_EH_PE_udata4",  /* Unsigned 4 bytes */
+	0x04: "DW_EH_PE_udata8",  /* Unsigned 8 bytes */
+	0x08: "DW_EH_PE_signed",  /* Signed pointer sized */
+	0x09: "DW_EH_PE_sleb128", /* Signed LEB128 */
+	0x0a: "DW_EH_PE_sdata2",  /* Signed 2 bytes */
+	0x0b: "DW_EH_PE_sdata4",  /* Signed 4 bytes */
+	0x0c: "DW_EH_PE_sdata8",  /* Signed 8 bytes */
+}
+
+// DWEHPEApplDecode maps the application (high nibble) of a DW_EH_PE_ pointer encoding to friendly name
+var DWEHPEApplDecode = map[uint8]string{
+	0x00: "DW_EH_PE_absptr",   /* Value is used as is */
+	0x10: "DW_EH_PE_pcrel",    /* Relative to the address of the value */
+	0x20: "DW_EH_PE_textrel",  /* Relative to the start of .text */
+	0x30: "DW_EH_PE_datarel",  /* Relative to the start of .eh_frame_hdr */
+	0x40: "DW_EH_PE_funcrel",  /* Relative to the start of the function */
+	0x50: "DW_EH_PE_aligned",  /* Pointer sized, aligned to its size */
+	0x80: "DW_EH_PE_indirect", /* Address of the real value, flag combined with the others */
+}
+
+const (
+	dwEHPEOmit     = 0xff
+	dwEHPEIndirect = 0x80
+)
+
+// CFADecode maps call frame instruction opcodes to friendly name. The three
+// opcodes with operands in their low six bits are listed with those bits clear
+var CFADecode = map[uint8]string{
+	0x00: "DW_CFA_nop",
+	0x01: "DW_CFA_set_loc",
+	0x02: "DW_CFA_advance_loc1",
+	0x03: "DW_CFA_advance_loc2",
+	0x04: "DW_CFA_advance_loc4",
+	0x05: "DW_CFA_offset_extended",
+	0x06: "DW_CFA_restore_extended",
+	0x07: "DW_CFA_undefined",
+	0x08: "DW_CFA_same_value",
+	0x09: "DW_CFA_register",
+	0x0a: "DW_CFA_remember_state",
+	0x0b: "DW_CFA_restore_state",
+	0x0c: "DW_CFA_def_cfa",
+	0x0d: "DW_CFA_def_cfa_register",
+	0x0e: "DW_CFA_def_cfa_offset",
+	0x0f: "DW_CFA_def_cfa_expression",
+	0x10: "DW_CFA_expression",
+	0x11: "DW_CFA_offset_extended_sf",
+	0x12: "DW_CFA_def_cfa_sf",
+	0x13: "DW_CFA_def_cfa_offset_sf",
+	0x14: "DW_CFA_val_offset",
+	0x15: "DW_CFA_val_offset_sf",
+	0x16: "DW_CFA_val_expression",
+	0x2d: "DW_CFA_GNU_window_save", /* DW_CFA_AARCH64_negate_ra_state on AArch64 */
+	0x2e: "DW_CFA_GNU_args_size",
+	0x2f: "DW_CFA_GNU_negative_offset_extended",
+	0x40: "DW_CFA_advance_loc",
+	0x80: "DW_CFA_offset",
+	0xc0: "DW_CFA_restore",
+}
+
+// CIE Common Information Entry of .eh_frame, shared by the FDEs that point to it
+type CIE struct {
+	Offset              uint64 /* Offset of the entry in .eh_frame */
+	Version             uint8  /* 1, or 3 for the DWARF 3 return register encoding */
+	Augmentation        string /* e.g. zR, zPLR */
+	CodeAlign           uint64 /* Factor applied to advance_loc operands */
+	DataAlign           int64  /* Factor applied to offset operands */
+	ReturnRegister      uint64 /* Column of the return address */
+	FDEEncoding         uint8  /* DW_EH_PE_ encoding of FDE addresses, 'R' */
+	LSDAEncoding        uint8  /* DW_EH_PE_ encoding of FDE LSDA pointers, 'L' */
+	PersonalityEncoding uint8  /* DW_EH_PE_ encoding of Personality, 'P' */
+	Personality         uint64 /* Personality routine, or the address holding it when the encoding is indirect */
+	SignalFrame         bool   /* 'S', the frame of a signal handler */
+	Instructions        []byte /* Initial call frame instructions */
+
+	ptrSize   uint8
+	endianess uint8
+}
+
+// FDE Frame Description Entry of .eh_frame, the unwinding rules of one function
+type FDE struct {
+	Offset       uint64 /* Offset of the entry in .eh_frame */
+	CIE          *CIE   /* The CIE the entry points to */
+	PCBegin      uint64 /* First address covered */
+	PCEnd        uint64 /* One past the last address covered */
+	LSDA         uint64 /* Language specific data area, 0 when absent */
+	Instructions []byte /* Call frame instructions */
+}
+
+// CFAInstruction a decoded call frame instruction
+type CFAInstruction struct {
+	Opcode     uint8   /* Opcode, with the operand bits of the primary opcodes cleared */
+	Op         string  /* Friendly name of Opcode */
+	Loc        uint64  /* Location after the instruction, advance_loc and set_loc move it */
+	Operands   []int64 /* Register numbers and offsets, offsets already multiplied by the alignment factors */
+	Expression []byte  /* DWARF expression of the _expression instructions */
+}
+
+// EHFrameHdrEntry an entry of the .eh_frame_hdr binary search table
+type EHFrameHdrEntry struct {
+	InitialLoc uint64 /* First address covered by the FDE */
+	FDEAddr    uint64 /* Virtual address of the FDE */
+}
+
+// EHFrameHdr the .eh_frame_hdr section, a sorted index of the FDEs
+type EHFrameHdr struct {
+	Address    uint64 /* Virtual address of the section */
+	Version    uint8
+	EHFramePtr uint64 /* Virtual address of .eh_frame */
+	FDECount   uint64
+	TableEnc   uint8 /* DW_EH_PE_ encoding of the table */
+	Table      []EHFrameHdrEntry
+}
+
+// EHFrame decoded .eh_frame section, the FDEs sorted by address
+type EHFrame struct {
+	Address uint64 /* Virtual address of the section */
+	CIEs    []*CIE
+	FDEs    []*FDE
+	Hdr     *EHFrameHdr /* nil when the file has no .eh_frame_hdr */
+
+	fdeByOffset map[uint64]*FDE
+}
+
+// ehData bytes of .eh_frame or .eh_frame_hdr and where they live
+type ehData struct {
+	data    []byte
+	addr    uint64 /* Virtual address of data[0] */
+	fileOff uint64 /* File offset of data[0] */
+}
+
+// EHFrame decodes .eh_frame and .eh_frame_hdr. Files without section headers
+// are decoded through the PT_GNU_EH_FRAME segment
+func (e ELF64) EHFrame() (*EHFrame, error) {
+	return readEHFrame(e)
+}
+
+// EHFrame decodes .eh_frame and .eh_frame_hdr. Files without section headers
+// are decoded through the PT_GNU_EH_FRAME segment
+func (e ELF32) EHFrame() (*EHFrame, error) {
+	return readEHFrame(e)
+}
+
+// FDEForAddr returns the FDE covering addr
+func (e ELF64) FDEForAddr(addr uint64) (*FDE, error) {
+	frame, err := readEHFrame(e)
+	if err != nil {
+		return nil, err
+	}
+	return frame.FDEForAddr(addr)
+}
+
+// FDEForAddr returns the FDE covering addr
+func (e ELF32) FDEForAddr(addr uint64) (*FDE, error) {
+	frame, err := readEHFrame(e)
+	if err != nil {
+		return nil, err
+	}
+	return frame.FDEForAddr(addr)
+}
+
+func readEHFrame(f File) (*EHFrame, error) {
+	endianess := f.Header().EIDATA
+	ptrSize := uint8(8)
+	if f.Class() == CLASS32BIT {
+		ptrSize = 4
+	}
+
+	var frame, hdr *ehData
+	sections := f.SectionData()
+	for i, h := range f.SectionTable() {
+		switch {
+		case h.SectionName == ".eh_frame" && h.SHType != SHTypeEncode["SHT_NOBITS"]:
+			frame = &ehData{data: sections[i].Data, addr: h.SHAddr, fileOff: h.SHOffset}
+		case h.SectionName == ".eh_frame_hdr":
+			hdr = &ehData{data: sections[i].Data, addr: h.SHAddr, fileOff: h.SHOffset}
+		}
+	}
+	if hdr == nil {
+		for _, ph := range f.Segments() {
+			if ph.PType == PTypeEncode["PT_GNU_EH_FRAME"] {
+				hdr = &ehData{data: ph.Data, addr: ph.PVaddr, fileOff: ph.POffset}
+			}
+		}
+	}
+
+	var ehHdr *EHFrameHdr
+	if hdr != nil {
+		var err error
+		if ehHdr, err = readEHFrameHdr(hdr, ptrSize, endianess); err != nil {
+			return nil, err
+		}
+	}
+	if frame == nil && ehHdr != nil {
+		// Without section headers .eh_frame runs from eh_frame_ptr to its
+		// zero terminator, which is within the same segment
+		space := newAddressSpace(f.Segments(), f.Class(), endianess)
+		r, err := space.Lookup(ehHdr.EHFramePtr)
+		if err != nil {
+			return nil, err
+		}
+		// p_memsz is not backed by anything, take only the segment's bytes in the file
+		segData := f.Segments()[r.Segment].Data
+		start := ehHdr.EHFramePtr - r.Start
+		if !r.FileBacked || start >= uint64(len(segData)) {
+			return nil, formatErr(int64(hdr.fileOff), ".eh_frame_hdr", nil, "eh_frame_ptr 0x%X not in the file", ehHdr.EHFramePtr)
+		}
+		end := r.End - r.Start
+		if end > uint64(len(segData)) {
+			end = uint64(len(segData))
+		}
+		fileOff, _ := space.Offset(ehHdr.EHFramePtr)
+		frame = &ehData{data: segData[start:end], addr: ehHdr.EHFramePtr, fileOff: fileOff}
+	}
+	if frame == nil {
+		return nil, ErrNoEHFrame
+	}
+
+	ehFrame, err := frame.decode(ptrSize, endianess)
+	if err != nil {
+		return nil, err
+	}
+	ehFrame.Hdr = ehHdr
+	return ehFrame, nil
+}
+
+func readEHFrameHdr(hdr *ehData, ptrSize uint8, endianess uint8) (*EHFrameHdr, error) {
+	b := &dwarfBuf{data: hdr.data, endianess: endianess, name: ".eh_frame_hdr"}
+	h := &EHFrameHdr{Address: hdr.addr}
+	h.Version = b.u8()
+	frameEnc := b.u8()
+	countEnc := b.u8()
+	h.TableEnc = b.u8()
+	if b.err == nil && h.Version != 1 {
+		return nil, formatErr(int64(hdr.fileOff), ".eh_frame_hdr", nil, "unsupported version %d", h.Version)
+	}
+	h.EHFramePtr = hdr.readEncoded(b, frameEnc, ptrSize, 0)
+	if countEnc != dwEHPEOmit && h.TableEnc != dwEHPEOmit {
+		h.FDECount = hdr.readEncoded(b, countEnc, ptrSize, 0)
+		if b.err == nil && h.FDECount > uint64(len(hdr.data)) {
+			return nil, formatErr(int64(hdr.fileOff), ".eh_frame_hdr", nil, "fde_count 0x%X past end of section", h.FDECount)
+		}
+		for i := uint64(0); i < h.FDECount && b.err == nil; i++ {
+			var entry EHFrameHdrEntry
+			entry.InitialLoc = hdr.readEncoded(b, h.TableEnc, ptrSize, 0)
+			entry.FDEAddr = hdr.readEncoded(b, h.TableEnc, ptrSize, 0)
+			h.Table = append(h.Table, entry)
+		}
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	return h, nil
+}
+
+// readEncoded reads a DW_EH_PE_ encoded pointer at the cursor. Data relative
+// values are relative to the start of d, which is only meaningful for
+// .eh_frame_hdr. funcBase is the start of the function for funcrel values
+func (d *ehData) readEncoded(b *dwarfBuf, enc uint8, ptrSize uint8, funcBase uint64) uint64 {
+	if enc == dwEHPEOmit {
+		return 0
+	}
+	pc := d.addr + uint64(b.off)
+	if enc&0x70 == 0x50 {
+		if pad := int(pc % uint64(ptrSize)); pad != 0 {
+			b.skip(int(ptrSize) - pad)
+		}
+		return b.addr(ptrSize)
+	}
+
+	var val uint64
+	switch enc & 0x0f {
+	case 0x00:
+		val = b.addr(ptrSize)
+	case 0x01:
+		val = b.uleb()
+	case 0x02:
+		val = uint64(b.u16())
+	case 0x03:
+		val = uint64(b.u32())
+	case 0x04, 0x0c:
+		val = b.u64()
+	case 0x08:
+		val = b.addr(ptrSize)
+		if ptrSize == 4 {
+			val = uint64(int32(val))
+		}
+	case 0x09:
+		val = uint64(b.sleb())
+	case 0x0a:
+		val = uint64(int16(b.u16()))
+	case 0x0b:
+		val = uint64(int32(b.u32()))
+	default:
+		b.fail("unknown pointer encoding 0x%X", enc)
+		return 0
+	}
+
+	switch enc & 0x70 {
+	case 0x10:
+		val += pc
+	case 0x30:
+		val += d.addr
+	case 0x40:
+		val += funcBase
+	}
+	if ptrSize == 4 {
+		val &= 0xffffffff
+	}
+	return val
+}
+
+func (d *ehData) decode(ptrSize uint8, endianess uint8) (*EHFrame, error) {
+	frame := &EHFrame{Address: d.addr, fdeByOffset: make(map[uint64]*FDE)}
+	cies := make(map[uint64]*CIE)
+	b := &dwarfBuf{data: d.data, endianess: endianess, name: ".eh_frame"}
+
+	// CIEs normally precede their FDEs but nothing requires it, so FDEs are
+	// decoded once every CIE is known
+	type fdeEntry struct {
+		off, cieOff uint64
+		start, end  int
+	}
+	var fdes []fdeEntry
+	for len(b.data)-b.off >= 4 {
+		off := uint64(b.off)
+		end := b.unitLength()
+		if b.err != nil {
+			return nil, b.err
+		}
+		// A zero length entry terminates .eh_frame
+		if end == int(off)+4 {
+			break
+		}
+		idOff := uint64(b.off)
+		id := b.u32()
+		if b.err != nil {
+			return nil, b.err
+		}
+		if end < b.off {
+			return nil, formatErr(int64(d.fileOff+off), "CIE/FDE", nil, "length 0x%X too short for the CIE id", end-int(off)-4)
+		}
+		if id == 0 {
+			cie, err := d.readCIE(b, end, off, ptrSize, endianess)
+			if err != nil {
+				return nil, err
+			}
+			cies[off] = cie
+			frame.CIEs = append(frame.CIEs, cie)
+		} else {
+			fdes = append(fdes, fdeEntry{off: off, cieOff: idOff - uint64(id), start: b.off, end: end})
+		}
+		b.off = end
+	}
+
+	for _, entry := range fdes {
+		cie, ok := cies[entry.cieOff]
+		if !ok {
+			return nil, formatErr(int64(d.fileOff+entry.off), "FDE", nil, "CIE pointer does not point at a CIE")
+		}
+		b.off = entry.start
+		fde, err := d.readFDE(b, entry.end, entry.off, cie)
+		if err != nil {
+			return nil, err
+		}
+		frame.FDEs = append(frame.FDEs, fde)
+		frame.fdeByOffset[entry.off] = fde
+	}
+
+	sort.SliceStable(frame.FDEs, func(i, j int) bool {
+		return frame.FDEs[i].PCBegin < frame.FDEs[j].PCBegin
+	})
+	return frame, nil
+}
+
+func (d *ehData) readCIE(b *dwarfBuf, end int, off uint64, ptrSize uint8, endianess uint8) (*CIE, error) {
+	cie := &CIE{Offset: off, LSDAEncoding: dwEHPEOmit, PersonalityEncoding: dwEHPEOmit, ptrSize: ptrSize, endianess: endianess}
+	cie.Version = b.u8()
+	if b.err == nil && cie.Version != 1 && cie.Version != 3 {
+		return nil, formatErr(int64(d.fileOff+off), "CIE", nil, "unsupported version %d", cie.Version)
+	}
+	cie.Augmentation = b.cstr()
+	if strings.HasPrefix(cie.Augmentation, "eh") {
+		b.addr(ptrSize)
+	}
+	cie.CodeAlign = b.uleb()
+	cie.DataAlign = b.sleb()
+	if cie.Version == 1 {
+		cie.ReturnRegister = uint64(b.u8())
+	} else {
+		cie.ReturnRegister = b.uleb()
+	}
+
+	if strings.HasPrefix(cie.Augmentation, "z") {
+		length := b.uleb()
+		if b.err == nil && (b.off > end || length > uint64(end-b.off)) {
+			return nil, formatErr(int64(d.fileOff+off), "CIE", nil, "augmentation data length 0x%X past end of CIE", length)
+		}
+		augEnd := b.off + int(length)
+		for _, c := range cie.Augmentation[1:] {
+			switch c {
+			case 'L':
+				cie.LSDAEncoding = b.u8()
+			case 'P':
+				cie.PersonalityEncoding = b.u8()
+				cie.Personality = d.readEncoded(b, cie.PersonalityEncoding&^dwEHPEIndirect, ptrSize, 0)
+			case 'R':
+				cie.FDEEncoding = b.u8()
+			case 'S':
+				cie.SignalFrame = true
+			}
+		}
+		// Skip augmentations this decoder doesn't know, the length covers them
+		b.off = augEnd
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.off > end {
+		return nil, formatErr(int64(d.fileOff+off), "CIE", nil, "fields run 0x%X bytes past end of CIE", b.off-end)
+	}
+	cie.Instructions = b.data[b.off:end]
+	return cie, nil
+}
+
+func (d *ehData) readFDE(b *dwarfBuf, end int, off uint64, cie *CIE) (*FDE, error) {
+	fde := &FDE{Offset: off, CIE: cie}
+	fde.PCBegin = d.readEncoded(b, cie.FDEEncoding&^dwEHPEIndirect, cie.ptrSize, 0)
+	// The range is a length, only the format of the encoding applies
+	fde.PCEnd = fde.PCBegin + d.readEncoded(b, cie.FDEEncoding&0x0f, cie.ptrSize, 0)
+	if strings.HasPrefix(cie.Augmentation, "z") {
+		length := b.uleb()
+		if b.err == nil && (b.off > end || length > uint64(end-b.off)) {
+			return nil, formatErr(int64(d.fileOff+off), "FDE", nil, "augmentation data length 0x%X past end of FDE", length)
+		}
+		augEnd := b.off + int(length)
+		if cie.LSDAEncoding != dwEHPEOmit && length != 0 {
+			fde.LSDA = d.readEncoded(b, cie.LSDAEncoding&^dwEHPEIndirect, cie.ptrSize, fde.PCBegin)
+		}
+		b.off = augEnd
+	}
+	if b.err != nil {
+		return nil, b.err
+	}
+	if b.off > end {
+		return nil, formatErr(int64(d.fileOff+off), "FDE", nil, "fields run 0x%X bytes past end of FDE", b.off-end)
+	}
+	fde.Instructions = b.data[b.off:end]
+	return fde, nil
+}
+
+// FDEForAddr returns the FDE covering addr, searching the .eh_frame_hdr
+// table when there is one
+func (f *EHFrame) FDEForAddr(addr uint64) (*FDE, error) {
+	if f.Hdr != nil && len(f.Hdr.Table) != 0 {
+		table := f.Hdr.Table
+		i := sort.Search(len(table), func(i int) bool {
+			return table[i].InitialLoc > addr
+		})
+		if i > 0 {
+			fde := f.fdeByOffset[table[i-1].FDEAddr-f.Address]
+			if fde != nil && addr >= fde.PCBegin && addr < fde.PCEnd {
+				return fde, nil
+			}
+		}
+		return nil, fmt.Errorf("elf: no FDE for 0x%X: %w", addr, ErrNoFDE)
+	}
+	i := sort.Search(len(f.FDEs), func(i int) bool {
+		return f.FDEs[i].PCBegin > addr
+	})
+	// FDEs of discarded sections can overlap, search back past empty ones
+	for ; i > 0; i-- {
+		fde := f.FDEs[i-1]
+		if addr >= fde.PCBegin && addr < fde.PCEnd {
+			return fde, nil
+		}
+		if fde.PCBegin != fde.PCEnd {
+			break
+		}
+	}
+	return nil, fmt.Errorf("elf: no FDE for 0x%X: %w", addr, ErrNoFDE)
+}
+
+// Decode decodes the initial call frame instructions of the CIE
+func (c *CIE) Decode() ([]CFAInstruction, error) {
+	return decodeCFA(c.Instructions, c, 0)
+}
+
+// Decode decodes the call frame instructions of the FDE
+func (f *FDE) Decode() ([]CFAInstruction, error) {
+	return decodeCFA(f.Instructions, f.CIE, f.PCBegin)
+}
+
+func decodeCFA(program []byte, cie *CIE, loc uint64) ([]CFAInstruction, error) {
+	// set_loc operands are decoded without their application, the instructions
+	// are not at a known address
+	d := &ehData{}
+	b := &dwarfBuf{data: program, endianess: cie.endianess, name: "call frame instructions"}
+	insts := make([]CFAInstruction, 0)
+	for b.off < len(b.data) {
+		op := b.u8()
+		inst := CFAInstruction{Opcode: op}
+		uleb := func() { inst.Operands = append(inst.Operands, int64(b.uleb())) }
+		offset := func() { inst.Operands = append(inst.Operands, int64(b.uleb())*cie.DataAlign) }
+		sleb := func() { inst.Operands = append(inst.Operands, b.sleb()*cie.DataAlign) }
+		switch op & 0xc0 {
+		case 0x40:
+			inst.Opcode = 0x40
+			loc += uint64(op&0x3f) * cie.CodeAlign
+		case 0x80:
+			inst.Opcode = 0x80
+			inst.Operands = append(inst.Operands, int64(op&0x3f))
+			offset()
+		case 0xc0:
+			inst.Opcode = 0xc0
+			inst.Operands = append(inst.Operands, int64(op&0x3f))
+		default:
+			switch op {
+			case 0x00, 0x0a, 0x0b, 0x2d:
+			case 0x01:
+				loc = d.readEncoded(b, cie.FDEEncoding&0x0f, cie.ptrSize, 0)
+			case 0x02:
+				loc += uint64(b.u8()) * cie.CodeAlign
+			case 0x03:
+				loc += uint64(b.u16()) * cie.CodeAlign
+			case 0x04:
+				loc += uint64(b.u32()) * cie.CodeAlign
+			case 0x05, 0x14:
+				uleb()
+				offset()
+			case 0x06, 0x07, 0x08, 0x0d, 0x2e:
+				uleb()
+			case 0x09, 0x0c:
+				uleb()
+				uleb()
+			case 0x0e:
+				uleb()
+			case 0x0f:
+				n := int(b.uleb())
+				if b.need(n) {
+					inst.Expression = b.data[b.off : b.off+n]
+					b.off += n
+				}
+			case 0x10, 0x16:
+				uleb()
+				n := int(b.uleb())
+				if b.need(n) {
+					inst.Expression = b.data[b.off : b.off+n]
+					b.off += n
+				}
+			case 0x11, 0x12, 0x15:
+				uleb()
+				sleb()
+			case 0x13:
+				sleb()
+			case 0x2f:
+				uleb()
+				inst.Operands = append(inst.Operands, -int64(b.uleb())*cie.DataAlign)
+			default:
+				b.fail("unknown call frame instruction 0x%X", op)
+			}
+		}
+		if b.err != nil {
+			return nil, b.err
+		}
+		inst.Op = CFADecode[inst.Opcode]
+		inst.Loc = loc
+		insts = append(insts, inst)
+	}
+	return insts, nil
+}
@@ -0,0 +1,87 @@
+package elf
+
+import (
+	"encoding/binary"
+	"errors"
+	"testing"
+)
+
+// ehRecord builds a .eh_frame record: a 32 bit length, the CIE id or CIE
+// pointer, then body
+func ehRecord(id uint32, body []byte) []byte {
+	rec := make([]byte, 8, 8+len(body))
+	binary.LittleEndian.PutUint32(rec, uint32(4+len(body)))
+	binary.LittleEndian.PutUint32(rec[4:], id)
+	return append(rec, body...)
+}
+
+// ehCIE a version 1 "zR" CIE with pc relative sdata4 FDE pointers, augLen
+// being the uleb128 augmentation data length
+func ehCIE(augLen []byte) []byte {
+	body := []byte{1, 'z', 'R', 0, 1, 0x78, 16}
+	body = append(body, augLen...)
+	return ehRecord(0, append(body, 0x1B, 0, 0, 0))
+}
+
+// ehFDE an FDE for the CIE at offset 0, placed at offset off
+func ehFDE(off int, augLen []byte) []byte {
+	body := []byte{0x10, 0, 0, 0, 0x20, 0, 0, 0}
+	body = append(body, augLen...)
+	return ehRecord(uint32(off+4), append(body, 0, 0, 0))
+}
+
+func decodeEHFrame(data []byte) (*EHFrame, error) {
+	d := &ehData{data: data, addr: 0x1000, fileOff: 0x200}
+	return d.decode(8, litteEndian)
+}
+
+func TestEHFrameDecode(t *testing.T) {
+	cie := ehCIE([]byte{1})
+	data := append(append([]byte{}, cie...), ehFDE(len(cie), []byte{0})...)
+	frame, err := decodeEHFrame(append(data, 0, 0, 0, 0))
+	if err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if len(frame.CIEs) != 1 || len(frame.FDEs) != 1 {
+		t.Fatalf("got %d CIEs and %d FDEs, want 1 and 1", len(frame.CIEs), len(frame.FDEs))
+	}
+	fde := frame.FDEs[0]
+	if want := uint64(0x1000 + len(cie) + 8 + 0x10); fde.PCBegin != want || fde.PCEnd != want+0x20 {
+		t.Errorf("FDE covers 0x%X-0x%X, want 0x%X-0x%X", fde.PCBegin, fde.PCEnd, want, want+0x20)
+	}
+}
+
+func TestEHFrameMalformed(t *testing.T) {
+	cie := ehCIE([]byte{1})
+	huge := []byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0x01}
+	// An FDE of length 1 whose CIE has no augmentation data, followed by
+	// enough bytes for its fields to be readable past the record
+	plainCIE := ehRecord(0, []byte{1, 0, 1, 0x78, 16, 0, 0, 0})
+	shortFDE := make([]byte, 40)
+	binary.LittleEndian.PutUint32(shortFDE, 1)
+	binary.LittleEndian.PutUint32(shortFDE[4:], uint32(len(plainCIE)+4))
+
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"record length below the CIE id", append(append([]byte{}, plainCIE...), shortFDE...)},
+		{"record length past end", ehRecord(0, []byte{1, 0})[:8]},
+		{"CIE augmentation length wraps", ehCIE(huge)},
+		{"CIE augmentation length past end", ehCIE([]byte{0x40})},
+		{"FDE augmentation length wraps", append(append([]byte{}, cie...), ehFDE(len(cie), huge)...)},
+		{"FDE augmentation length past end", append(append([]byte{}, cie...), ehFDE(len(cie), []byte{0x40})...)},
+		{"CIE fields past end of section", ehRecord(0, []byte{1, 'z', 'R', 0, 1})},
+		{"CIE fields past end of record", append(ehRecord(0, []byte{1, 0}), make([]byte, 16)...)},
+		{"FDE without CIE", ehFDE(0, []byte{0})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := decodeEHFrame(tt.data)
+			var fe *FormatError
+			if !errors.As(err, &fe) {
+				t.Fatalf("got error %v, want a FormatError", err)
+			}
+		})
+	}
+}
@@ -1,5 +1,17 @@
 package elf
 
+// File is the class agnostic view of an ELF file. 32 bit structures are
+// widened to their 64 bit counterparts so the same code can process both.
+type File interface {
+	Class() uint8                      /* CLASS32BIT or CLASS64BIT */
+	Header() FileHeader64              /* File header */
+	Segments() []ProgramHeader64       /* Program header table */
+	SectionTable() []SectionHeader64   /* Section header table */
+	SectionData() []Section            /* Section contents, parallel to SectionTable */
+	Symbols() ([]Symbol, error)        /* Entries of .symtab */
+	DynamicSymbols() ([]Symbol, error) /* Entries of .dynsym */
+}
+
 // ELF64 format
 type ELF64 struct {
 	FileHeader     FileHeader64
@@ -7,3 +19,69 @@ type ELF64 struct {
 	Sections       []Section
 	SectionHeaders []SectionHeader64
 }
+
+// ELF32 format
+type ELF32 struct {
+	FileHeader     FileHeader32
+	ProgramHeaders []ProgramHeader32
+	Sections       []Section
+	SectionHeaders []SectionHeader32
+}
+
+// Class returns the EI_CLASS of the file
+func (e ELF64) Class() uint8 {
+	return CLASS64BIT
+}
+
+// Header returns the file header
+func (e ELF64) Header() FileHeader64 {
+	return e.FileHeader
+}
+
+// Segments returns the program headers
+func (e ELF64) Segments() []ProgramHeader64 {
+	return e.ProgramHeaders
+}
+
+// SectionTable returns the section headers
+func (e ELF64) SectionTable() []SectionHeader64 {
+	return e.SectionHeaders
+}
+
+// SectionData returns the section contents
+func (e ELF64) SectionData() []Section {
+	return e.Sections
+}
+
+// Class returns the EI_CLASS of the file
+func (e ELF32) Class() uint8 {
+	return CLASS32BIT
+}
+
+// Header returns the file header widened to a FileHeader64
+func (e ELF32) Header() FileHeader64 {
+	return e.FileHeader.widen()
+}
+
+// Segments returns the program headers widened to ProgramHeader64s
+func (e ELF32) Segments() []ProgramHeader64 {
+	pHead := make([]ProgramHeader64, len(e.ProgramHeaders))
+	for i, h := range e.ProgramHeaders {
+		pHead[i] = h.widen()
+	}
+	return pHead
+}
+
+// SectionTable returns the section headers widened to SectionHeader64s
+func (e ELF32) SectionTable() []SectionHeader64 {
+	sHead := make([]SectionHeader64, len(e.SectionHeaders))
+	for i, h := range e.SectionHeaders {
+		sHead[i] = h.widen()
+	}
+	return sHead
+}
+
+// SectionData returns the section contents
+func (e ELF32) SectionData() []Section {
+	return e.Sections
+}
@@ -1,108 +1,496 @@
 package elf
 
 import (
-	"fmt"
+	"bytes"
+	"io"
 	"os"
 )
 
 const (
-	fileHeader64Size = 0x40
-	fileHeader32Size = 0x34
+	fileHeader64Size    = 0x40
+	fileHeader32Size    = 0x34
+	programHeader64Size = 0x38
+	programHeader32Size = 0x20
+	sectionHeader64Size = 0x40
+	sectionHeader32Size = 0x28
 )
 
-// Reader64 Provides functions for population ELF structs from a filename
+// pnXNum e_phnum escape, the real program header count is in sh_info of section header 0
+const pnXNum = 0xffff
+
+// Open reads the ELF file at name, choosing the 32 or 64 bit reader based on
+// its EI_CLASS
+func Open(name string) (File, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	return NewFile(f, info.Size())
+}
+
+// OpenBytes reads an ELF image held in memory
+func OpenBytes(b []byte) (File, error) {
+	return NewFile(bytes.NewReader(b), int64(len(b)))
+}
+
+// NewFile reads an ELF image of size bytes from r, choosing the 32 or 64 bit
+// reader based on its EI_CLASS
+func NewFile(r io.ReaderAt, size int64) (File, error) {
+	ident := make([]byte, 5)
+	if err := checkedRead(r, ident, 0x00, "e_ident"); err != nil {
+		return nil, err
+	}
+	if readu32be(ident, 0) != elfMagic {
+		return nil, formatErr(0, "e_ident", ErrNotELF, "bad magic number 0x%08X", readu32be(ident, 0))
+	}
+
+	switch ident[0x04] {
+	case CLASS32BIT:
+		var e Reader32
+		return e.FromReaderAt(r, size)
+	case CLASS64BIT:
+		var e Reader64
+		return e.FromReaderAt(r, size)
+	}
+	return nil, formatErr(0x04, "e_ident", ErrUnsupportedClass, "EI_CLASS 0x%X", ident[0x04])
+}
+
+// Reader64 Provides functions for population ELF64 structs from a filename,
+// an io.ReaderAt or a byte slice
 type Reader64 struct {
 	FileName  string // Name of ELF file
-	filePtr   *os.File
+	reader    io.ReaderAt
+	fileSize  int64
 	elfStruct ELF64
+	shnum     uint64 /* Section count, from section header 0 when e_shnum is 0 */
+	phnum     uint64 /* Program header count, from section header 0 when e_phnum is PN_XNUM */
+	shstrndx  uint64 /* Section name table index, from section header 0 when e_shstrndx is SHN_XINDEX */
 }
 
-// FromFile Initializes an Reader struct from the filename
-func (e *Reader64) FromFile(string) ELF64 {
-	e.openFile()
-	e.elfStruct.FileHeader = e.readFileHead64()
-	e.elfStruct.ProgramHeaders = e.readProgramHeaders64()
-	sheaders, sections := e.readSectionHeaders64()
+// FromFile Initializes an ELF64 struct from the filename
+func (e *Reader64) FromFile(string) (ELF64, error) {
+	file, err := os.Open(e.FileName)
+	if err != nil {
+		return ELF64{}, err
+	}
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return ELF64{}, err
+	}
+	return e.FromReaderAt(file, info.Size())
+}
+
+// FromBytes Initializes an ELF64 struct from an image held in memory
+func (e *Reader64) FromBytes(b []byte) (ELF64, error) {
+	return e.FromReaderAt(bytes.NewReader(b), int64(len(b)))
+}
+
+// FromReaderAt Initializes an ELF64 struct from the size bytes readable from r
+func (e *Reader64) FromReaderAt(r io.ReaderAt, size int64) (ELF64, error) {
+	e.reader = r
+	e.fileSize = size
+	e.elfStruct = ELF64{}
+
+	var err error
+	if e.elfStruct.FileHeader, err = e.readFileHead64(); err != nil {
+		return ELF64{}, err
+	}
+	if err = e.readExtendedNumbering(); err != nil {
+		return ELF64{}, err
+	}
+	if e.elfStruct.ProgramHeaders, err = e.readProgramHeaders64(); err != nil {
+		return ELF64{}, err
+	}
+	sheaders, sections, err := e.readSectionHeaders64()
+	if err != nil {
+		return ELF64{}, err
+	}
 	e.elfStruct.SectionHeaders = sheaders
 	e.elfStruct.Sections = sections
-	return e.elfStruct
+	return e.elfStruct, nil
+}
+
+func (e *Reader64) readFileHead64() (FileHeader64, error) {
+	var h FileHeader64
+	readBuf := make([]byte, fileHeader64Size)
+	if err := checkedRead(e.reader, readBuf, 0x00, "file header"); err != nil {
+		return h, err
+	}
+
+	err := h.FromBuffer(readBuf)
+	return h, err
 }
 
-func (e *Reader64) openFile() {
-	program := e.FileName
-	file, err := os.Open(program)
+// readExtendedNumbering resolves the section count, e_shstrndx and program
+// header count, which live in section header 0 when they do not fit the file header
+func (e *Reader64) readExtendedNumbering() error {
+	fh := e.elfStruct.FileHeader
+	e.shnum = uint64(fh.EShnum)
+	e.phnum = uint64(fh.EPhnum)
+	e.shstrndx = uint64(fh.EShstrndx)
+	if fh.EShoff == 0 || (fh.EShnum != 0 && fh.EPhnum != pnXNum && fh.EShstrndx != SHNEncode["SHN_XINDEX"]) {
+		return nil
+	}
+	if fh.EShentsize < sectionHeader64Size {
+		return formatErr(0x3a, "file header", nil, "e_shentsize 0x%X too small", fh.EShentsize)
+	}
+	buf := make([]byte, fh.EShentsize)
+	if err := checkedRead(e.reader, buf, int64(fh.EShoff), "section header"); err != nil {
+		return err
+	}
+	var h SectionHeader64
+	h.FromBuffer(buf, fh.EIDATA)
+	if fh.EShnum == 0 {
+		e.shnum = uint64(h.SHSize)
+	}
+	if fh.EPhnum == pnXNum {
+		e.phnum = uint64(h.SHInfo)
+	}
+	if fh.EShstrndx == SHNEncode["SHN_XINDEX"] {
+		e.shstrndx = uint64(h.SHLink)
+	}
+	if e.shnum > uint64(e.fileSize)/uint64(fh.EShentsize) {
+		return formatErr(int64(fh.EShoff), "section header", nil, "sh_size 0x%X sections past end of file", h.SHSize)
+	}
+	return nil
+}
+
+func (e *Reader64) readProgramHeaders64() ([]ProgramHeader64, error) {
+	fh := e.elfStruct.FileHeader
+	if e.phnum == 0 {
+		return []ProgramHeader64{}, nil
+	}
+	if fh.EPhentsize < programHeader64Size {
+		return nil, formatErr(0x36, "file header", nil, "e_phentsize 0x%X too small", fh.EPhentsize)
+	}
+	if !inFile(fh.EPhoff, uint64(fh.EPhentsize)*e.phnum, e.fileSize) {
+		return nil, formatErr(int64(fh.EPhoff), "program header table", nil, "0x%X entries past end of file", e.phnum)
+	}
+
+	pHead := make([]ProgramHeader64, int(e.phnum))
+	for i := 0; i < int(e.phnum); i++ {
+		offset := fh.EPhoff + uint64(int(fh.EPhentsize)*i)
+		h, err := e.readProgramHeader64(offset)
+		if err != nil {
+			return nil, err
+		}
+		pHead[i] = h
+	}
+	return pHead, nil
+}
+
+func (e *Reader64) readProgramHeader64(offset uint64) (ProgramHeader64, error) {
+	var h ProgramHeader64
+	readBuf := make([]byte, e.elfStruct.FileHeader.EPhentsize)
+	if err := checkedRead(e.reader, readBuf, int64(offset), "program header"); err != nil {
+		return h, err
+	}
+
+	h.FromBuffer(readBuf, e.elfStruct.FileHeader.EIDATA)
+	h.FileOffset = offset
+
+	// A truncated file, typically a core, keeps the part of the segment that
+	// is present, reads of the rest through AddressSpace fail
+	h.Data = make([]byte, presentSize(h.POffset, h.PFilesz, e.fileSize))
+	if err := checkedRead(e.reader, h.Data, int64(h.POffset), "segment data"); err != nil {
+		return h, err
+	}
+	return h, nil
+}
+
+func (e *Reader64) readSectionHeaders64() ([]SectionHeader64, []Section, error) {
+	fh := e.elfStruct.FileHeader
+	if e.shnum == 0 {
+		return []SectionHeader64{}, []Section{}, nil
+	}
+	if fh.EShentsize < sectionHeader64Size {
+		return nil, nil, formatErr(0x3a, "file header", nil, "e_shentsize 0x%X too small", fh.EShentsize)
+	}
+	if !inFile(fh.EShoff, uint64(fh.EShentsize)*e.shnum, e.fileSize) {
+		return nil, nil, formatErr(int64(fh.EShoff), "section header table", nil, "0x%X entries past end of file", e.shnum)
+	}
+	if e.shstrndx >= e.shnum {
+		return nil, nil, formatErr(0x3e, "file header", nil, "e_shstrndx 0x%X out of range", e.shstrndx)
+	}
+
+	sHead := make([]SectionHeader64, int(e.shnum))
+	sections := make([]Section, int(e.shnum))
+	for i := 0; i < int(e.shnum); i++ {
+		offset := fh.EShoff + uint64(int(fh.EShentsize)*i)
+		h, s, err := e.readSectionHead64(offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		sHead[i], sections[i] = h, s
+	}
+
+	// e_shstrndx of SHN_UNDEF means the sections have no names
+	if e.shstrndx == 0 {
+		return sHead, sections, nil
+	}
+	nameData := sections[e.shstrndx].Data
+	for i, section := range sHead {
+		name, ok := readCStr(nameData, int(section.SHName))
+		if !ok {
+			return nil, nil, formatErr(int64(fh.EShoff)+int64(i)*int64(fh.EShentsize), "section header", nil, "sh_name 0x%X out of range", section.SHName)
+		}
+		sHead[i].SectionName = name
+		sections[i].name = name
+	}
+	return sHead, sections, nil
+}
+
+func (e *Reader64) readSectionHead64(offset uint64) (SectionHeader64, Section, error) {
+	var h SectionHeader64
+	readBuf := make([]byte, e.elfStruct.FileHeader.EShentsize)
+	if err := checkedRead(e.reader, readBuf, int64(offset), "section header"); err != nil {
+		return h, Section{}, err
+	}
+	h.FromBuffer(readBuf, e.elfStruct.FileHeader.EIDATA)
+
+	// SHT_NOBITS sections occupy no space in the file
+	if h.SHType == SHTypeEncode["SHT_NOBITS"] {
+		return h, Section{Data: []byte{}, startAddr: offset, archBits: 64, flags: uint64(h.SHFlags), endianess: e.elfStruct.FileHeader.EIDATA}, nil
+	}
+	if !inFile(h.SHOffset, h.SHSize, e.fileSize) {
+		return h, Section{}, formatErr(int64(offset), "section header", nil, "section data 0x%X+0x%X past end of file", h.SHOffset, h.SHSize)
+	}
+	dataBuf := make([]byte, h.SHSize)
+	if err := checkedRead(e.reader, dataBuf, int64(h.SHOffset), "section data"); err != nil {
+		return h, Section{}, err
+	}
+	return h, Section{Data: dataBuf, startAddr: offset, archBits: 64, flags: uint64(h.SHFlags), endianess: e.elfStruct.FileHeader.EIDATA}, nil
+}
+
+// Reader32 Provides functions for population ELF32 structs from a filename,
+// an io.ReaderAt or a byte slice
+type Reader32 struct {
+	FileName  string // Name of ELF file
+	reader    io.ReaderAt
+	fileSize  int64
+	elfStruct ELF32
+	shnum     uint64 /* Section count, from section header 0 when e_shnum is 0 */
+	phnum     uint64 /* Program header count, from section header 0 when e_phnum is PN_XNUM */
+	shstrndx  uint64 /* Section name table index, from section header 0 when e_shstrndx is SHN_XINDEX */
+}
+
+// FromFile Initializes an ELF32 struct from the filename
+func (e *Reader32) FromFile(string) (ELF32, error) {
+	file, err := os.Open(e.FileName)
 	if err != nil {
-		fmt.Fprint(os.Stderr, err)
-		os.Exit(1)
+		return ELF32{}, err
 	}
-	if e.checkClass(file) != CLASS64BIT {
-		fmt.Fprint(os.Stderr, "Only 64 bit ELF supported\n")
-		os.Exit(1)
+	defer file.Close()
+	info, err := file.Stat()
+	if err != nil {
+		return ELF32{}, err
 	}
-	e.filePtr = file
+	return e.FromReaderAt(file, info.Size())
 }
 
-func (e *Reader64) checkClass(f *os.File) uint8 {
-	b := make([]byte, 1)
-	checkedRead(f, b, 0x04)
-	return uint8(b[0])
+// FromBytes Initializes an ELF32 struct from an image held in memory
+func (e *Reader32) FromBytes(b []byte) (ELF32, error) {
+	return e.FromReaderAt(bytes.NewReader(b), int64(len(b)))
 }
 
-func (e *Reader64) readFileHead64() FileHeader64 {
-	readBuf := make([]byte, fileHeader64Size)
-	checkedRead(e.filePtr, readBuf, 0x00)
+// FromReaderAt Initializes an ELF32 struct from the size bytes readable from r
+func (e *Reader32) FromReaderAt(r io.ReaderAt, size int64) (ELF32, error) {
+	e.reader = r
+	e.fileSize = size
+	e.elfStruct = ELF32{}
 
-	var h FileHeader64
-	h.FromBuffer(readBuf)
-	return h
+	var err error
+	if e.elfStruct.FileHeader, err = e.readFileHead32(); err != nil {
+		return ELF32{}, err
+	}
+	if err = e.readExtendedNumbering(); err != nil {
+		return ELF32{}, err
+	}
+	if e.elfStruct.ProgramHeaders, err = e.readProgramHeaders32(); err != nil {
+		return ELF32{}, err
+	}
+	sheaders, sections, err := e.readSectionHeaders32()
+	if err != nil {
+		return ELF32{}, err
+	}
+	e.elfStruct.SectionHeaders = sheaders
+	e.elfStruct.Sections = sections
+	return e.elfStruct, nil
 }
 
-func (e *Reader64) readProgramHeaders64() []ProgramHeader64 {
-	pHead := make([]ProgramHeader64, int(e.elfStruct.FileHeader.EPhnum))
-	for i := 0; i < int(e.elfStruct.FileHeader.EPhnum); i++ {
-		offset := e.elfStruct.FileHeader.EPhoff + uint64(int(e.elfStruct.FileHeader.EPhentsize)*i)
-		pHead[i] = e.readProgramHeader64(offset)
+func (e *Reader32) readFileHead32() (FileHeader32, error) {
+	var h FileHeader32
+	readBuf := make([]byte, fileHeader32Size)
+	if err := checkedRead(e.reader, readBuf, 0x00, "file header"); err != nil {
+		return h, err
 	}
-	return pHead
+
+	err := h.FromBuffer(readBuf)
+	return h, err
 }
 
-func (e *Reader64) readProgramHeader64(offset uint64) ProgramHeader64 {
-	var h ProgramHeader64
-	if e.elfStruct.FileHeader.EPhentsize == 0 {
-		return h
+// readExtendedNumbering resolves the section count, e_shstrndx and program
+// header count, which live in section header 0 when they do not fit the file header
+func (e *Reader32) readExtendedNumbering() error {
+	fh := e.elfStruct.FileHeader
+	e.shnum = uint64(fh.EShnum)
+	e.phnum = uint64(fh.EPhnum)
+	e.shstrndx = uint64(fh.EShstrndx)
+	if fh.EShoff == 0 || (fh.EShnum != 0 && fh.EPhnum != pnXNum && fh.EShstrndx != SHNEncode["SHN_XINDEX"]) {
+		return nil
+	}
+	if fh.EShentsize < sectionHeader32Size {
+		return formatErr(0x2E, "file header", nil, "e_shentsize 0x%X too small", fh.EShentsize)
+	}
+	buf := make([]byte, fh.EShentsize)
+	if err := checkedRead(e.reader, buf, int64(fh.EShoff), "section header"); err != nil {
+		return err
+	}
+	var h SectionHeader32
+	h.FromBuffer(buf, fh.EIDATA)
+	if fh.EShnum == 0 {
+		e.shnum = uint64(h.SHSize)
 	}
+	if fh.EPhnum == pnXNum {
+		e.phnum = uint64(h.SHInfo)
+	}
+	if fh.EShstrndx == SHNEncode["SHN_XINDEX"] {
+		e.shstrndx = uint64(h.SHLink)
+	}
+	if e.shnum > uint64(e.fileSize)/uint64(fh.EShentsize) {
+		return formatErr(int64(fh.EShoff), "section header", nil, "sh_size 0x%X sections past end of file", h.SHSize)
+	}
+	return nil
+}
+
+func (e *Reader32) readProgramHeaders32() ([]ProgramHeader32, error) {
+	fh := e.elfStruct.FileHeader
+	if e.phnum == 0 {
+		return []ProgramHeader32{}, nil
+	}
+	if fh.EPhentsize < programHeader32Size {
+		return nil, formatErr(0x2A, "file header", nil, "e_phentsize 0x%X too small", fh.EPhentsize)
+	}
+	if !inFile(uint64(fh.EPhoff), uint64(fh.EPhentsize)*e.phnum, e.fileSize) {
+		return nil, formatErr(int64(fh.EPhoff), "program header table", nil, "0x%X entries past end of file", e.phnum)
+	}
+
+	pHead := make([]ProgramHeader32, int(e.phnum))
+	for i := 0; i < int(e.phnum); i++ {
+		offset := uint64(fh.EPhoff) + uint64(int(fh.EPhentsize)*i)
+		h, err := e.readProgramHeader32(offset)
+		if err != nil {
+			return nil, err
+		}
+		pHead[i] = h
+	}
+	return pHead, nil
+}
+
+func (e *Reader32) readProgramHeader32(offset uint64) (ProgramHeader32, error) {
+	var h ProgramHeader32
 	readBuf := make([]byte, e.elfStruct.FileHeader.EPhentsize)
-	checkedRead(e.filePtr, readBuf, int64(offset))
+	if err := checkedRead(e.reader, readBuf, int64(offset), "program header"); err != nil {
+		return h, err
+	}
 
 	h.FromBuffer(readBuf, e.elfStruct.FileHeader.EIDATA)
 	h.FileOffset = offset
-	return h
+
+	// A truncated file, typically a core, keeps the part of the segment that
+	// is present, reads of the rest through AddressSpace fail
+	h.Data = make([]byte, presentSize(uint64(h.POffset), uint64(h.PFilesz), e.fileSize))
+	if err := checkedRead(e.reader, h.Data, int64(h.POffset), "segment data"); err != nil {
+		return h, err
+	}
+	return h, nil
 }
 
-func (e *Reader64) readSectionHeaders64() ([]SectionHeader64, []Section) {
-	sHead := make([]SectionHeader64, int(e.elfStruct.FileHeader.EShnum))
-	sections := make([]Section, int(e.elfStruct.FileHeader.EShnum))
-	for i := 0; i < int(e.elfStruct.FileHeader.EShnum); i++ {
-		offset := e.elfStruct.FileHeader.EShoff + uint64(int(e.elfStruct.FileHeader.EShentsize)*i)
-		sHead[i], sections[i] = e.readSectionHead64(offset)
+func (e *Reader32) readSectionHeaders32() ([]SectionHeader32, []Section, error) {
+	fh := e.elfStruct.FileHeader
+	if e.shnum == 0 {
+		return []SectionHeader32{}, []Section{}, nil
+	}
+	if fh.EShentsize < sectionHeader32Size {
+		return nil, nil, formatErr(0x2E, "file header", nil, "e_shentsize 0x%X too small", fh.EShentsize)
+	}
+	if !inFile(uint64(fh.EShoff), uint64(fh.EShentsize)*e.shnum, e.fileSize) {
+		return nil, nil, formatErr(int64(fh.EShoff), "section header table", nil, "0x%X entries past end of file", e.shnum)
+	}
+	if e.shstrndx >= e.shnum {
+		return nil, nil, formatErr(0x32, "file header", nil, "e_shstrndx 0x%X out of range", e.shstrndx)
+	}
+
+	sHead := make([]SectionHeader32, int(e.shnum))
+	sections := make([]Section, int(e.shnum))
+	for i := 0; i < int(e.shnum); i++ {
+		offset := uint64(fh.EShoff) + uint64(int(fh.EShentsize)*i)
+		h, s, err := e.readSectionHead32(offset)
+		if err != nil {
+			return nil, nil, err
+		}
+		sHead[i], sections[i] = h, s
 	}
 
-	if e.elfStruct.FileHeader.EShnum != 0 {
-		nameDataOffset := sHead[e.elfStruct.FileHeader.EShstrndx].SHOffset
-		for i, section := range sHead {
-			sHead[i].SectionName = readStr(e.filePtr, int(nameDataOffset+uint64(section.SHName)))
+	// e_shstrndx of SHN_UNDEF means the sections have no names
+	if e.shstrndx == 0 {
+		return sHead, sections, nil
+	}
+	nameData := sections[e.shstrndx].Data
+	for i, section := range sHead {
+		name, ok := readCStr(nameData, int(section.SHName))
+		if !ok {
+			return nil, nil, formatErr(int64(fh.EShoff)+int64(i)*int64(fh.EShentsize), "section header", nil, "sh_name 0x%X out of range", section.SHName)
 		}
+		sHead[i].SectionName = name
+		sections[i].name = name
 	}
-	return sHead, sections
+	return sHead, sections, nil
 }
 
-func (e *Reader64) readSectionHead64(offset uint64) (SectionHeader64, Section) {
+func (e *Reader32) readSectionHead32(offset uint64) (SectionHeader32, Section, error) {
+	var h SectionHeader32
 	readBuf := make([]byte, e.elfStruct.FileHeader.EShentsize)
-	checkedRead(e.filePtr, readBuf, int64(offset))
-	var h SectionHeader64
+	if err := checkedRead(e.reader, readBuf, int64(offset), "section header"); err != nil {
+		return h, Section{}, err
+	}
 	h.FromBuffer(readBuf, e.elfStruct.FileHeader.EIDATA)
 
+	// SHT_NOBITS sections occupy no space in the file
+	if h.SHType == SHTypeEncode["SHT_NOBITS"] {
+		return h, Section{Data: []byte{}, startAddr: offset, archBits: 32, flags: uint64(h.SHFlags), endianess: e.elfStruct.FileHeader.EIDATA}, nil
+	}
+	if !inFile(uint64(h.SHOffset), uint64(h.SHSize), e.fileSize) {
+		return h, Section{}, formatErr(int64(offset), "section header", nil, "section data 0x%X+0x%X past end of file", h.SHOffset, h.SHSize)
+	}
 	dataBuf := make([]byte, h.SHSize)
-	checkedRead(e.filePtr, dataBuf, int64(h.SHOffset))
-	return h, Section{Data: dataBuf, startAddr: offset, archBits: int(e.elfStruct.FileHeader.EIDATA)}
+	if err := checkedRead(e.reader, dataBuf, int64(h.SHOffset), "section data"); err != nil {
+		return h, Section{}, err
+	}
+	return h, Section{Data: dataBuf, startAddr: offset, archBits: 32, flags: uint64(h.SHFlags), endianess: e.elfStruct.FileHeader.EIDATA}, nil
+}
+
+// inFile reports whether the range [offset, offset+size) lies within a file
+// of fileSize bytes
+func inFile(offset, size uint64, fileSize int64) bool {
+	end := offset + size
+	return end >= offset && end <= uint64(fileSize)
+}
+
+// presentSize returns how many of the size bytes at offset lie within a file
+// of fileSize bytes
+func presentSize(offset, size uint64, fileSize int64) uint64 {
+	if offset >= uint64(fileSize) {
+		return 0
+	}
+	if size > uint64(fileSize)-offset {
+		return uint64(fileSize) - offset
+	}
+	return size
 }
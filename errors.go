@@ -0,0 +1,86 @@
+package elf
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNotELF is returned when the input does not start with the ELF magic number
+	ErrNotELF = errors.New("not an ELF file")
+	// ErrUnsupportedClass is returned when EI_CLASS is neither 32 nor 64 bit,
+	// or is not the class expected by the reader being used
+	ErrUnsupportedClass = errors.New("unsupported ELF class")
+	// ErrNoSymbols is returned when the requested symbol table is not present
+	ErrNoSymbols = errors.New("no symbol section")
+	// ErrSymbolNotFound is returned when a symbol lookup has no match
+	ErrSymbolNotFound = errors.New("symbol not found")
+	// ErrNoDynamic is returned when the file has no dynamic section
+	ErrNoDynamic = errors.New("no dynamic section")
+	// ErrNoBuildID is returned when the file has no NT_GNU_BUILD_ID note
+	ErrNoBuildID = errors.New("no build-id note")
+	// ErrUnmapped is returned for virtual addresses outside every PT_LOAD segment
+	ErrUnmapped = errors.New("address not mapped")
+	// ErrZeroFill is returned for virtual addresses in the zero filled (bss)
+	// part of a segment, which has no bytes in the file
+	ErrZeroFill = errors.New("address in zero filled memory")
+	// ErrNoHashTable is returned when the file has no hash table of the requested kind
+	ErrNoHashTable = errors.New("no hash table")
+	// ErrNoDWARF is returned when the file has no .debug_info section
+	ErrNoDWARF = errors.New("no DWARF debugging information")
+	// ErrNoDWARFEntry is returned when no DWARF unit, function or line covers an address
+	ErrNoDWARFEntry = errors.New("no DWARF entry for address")
+	// ErrNotCompressed is returned when asking for the compression header of a plain section
+	ErrNotCompressed = errors.New("section not compressed")
+	// ErrUnsupportedCompression is returned for ch_type values other than zlib and zstd
+	ErrUnsupportedCompression = errors.New("unsupported compression type")
+	// ErrNoEHFrame is returned when the file has neither .eh_frame nor PT_GNU_EH_FRAME
+	ErrNoEHFrame = errors.New("no .eh_frame section")
+	// ErrNoFDE is returned when no FDE covers an address
+	ErrNoFDE = errors.New("no FDE for address")
+	// ErrNotCore is returned when core file analysis is asked of a file that isn't ET_CORE
+	ErrNotCore = errors.New("not a core file")
+	// ErrNotDumped is returned for core file addresses whose contents were not written to the core
+	ErrNotDumped = errors.New("memory not dumped in core")
+	// ErrNoGoBuildInfo is returned when the file has no Go build information
+	ErrNoGoBuildInfo = errors.New("no Go build information")
+	// ErrNoGoPCLNTab is returned when the file has no Go function table
+	ErrNoGoPCLNTab = errors.New("no Go pclntab")
+	// ErrNoGoFunc is returned when no Go function covers an address
+	ErrNoGoFunc = errors.New("no Go function for address")
+	// ErrNotArchive is returned when the input does not start with the ar archive magic
+	ErrNotArchive = errors.New("not an ar archive")
+	// ErrNoMember is returned when an archive has no member of the requested name
+	ErrNoMember = errors.New("archive member not found")
+	// ErrNoPLT is returned when the file has no GOT slots filled by the dynamic linker
+	ErrNoPLT = errors.New("no PLT or GOT relocations")
+	// ErrNoPLTEntry is returned when no PLT stub or GOT slot covers an address
+	ErrNoPLTEntry = errors.New("no PLT stub or GOT slot for address")
+	// ErrNoSection is returned when no section matches a name or address query
+	ErrNoSection = errors.New("section not found")
+)
+
+// FormatError describes a malformed structure found while parsing
+type FormatError struct {
+	Off    int64  /* Offset in the file of the offending structure */
+	Struct string /* Name of the structure being decoded */
+	Msg    string /* Description of the problem */
+	Err    error  /* Underlying error, if any */
+}
+
+func (e *FormatError) Error() string {
+	msg := fmt.Sprintf("elf: %s at offset 0x%X: %s", e.Struct, e.Off, e.Msg)
+	if e.Err != nil {
+		msg += ": " + e.Err.Error()
+	}
+	return msg
+}
+
+// Unwrap returns the underlying error so errors.Is can match ErrNotELF and friends
+func (e *FormatError) Unwrap() error {
+	return e.Err
+}
+
+func formatErr(off int64, structure string, err error, format string, args ...interface{}) *FormatError {
+	return &FormatError{Off: off, Struct: structure, Msg: fmt.Sprintf(format, args...), Err: err}
+}
@@ -17,7 +17,7 @@ func Exec(code []byte) {
 
 	err := makeExecutable(codeAddr, size)
 	if err != 0 {
-		fmt.Fprintf(os.Stderr, "Error with mprotect: %s\n", err)
+		fmt.Fprintf(os.Stderr, "Error with mprotect: %s\n", syscall.Errno(err))
 	}
 
 	codePtr := unsafe.Pointer(codeAddr)
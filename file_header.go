@@ -2,6 +2,8 @@ package elf
 
 import "fmt"
 
+const elfMagic = 0x7F454C46 /* "\x7fELF" */
+
 // EICLASS
 const (
 	CLASS32BIT = 1 + iota /* 32 bit ELF */
@@ -56,6 +58,9 @@ var OSABIEncode = map[string]uint8{
 	"Fenix OS":                     0x10,
 	"CloudABI":                     0x11,
 	"Stratus Technologies OpenVOS": 0x12,
+	"ARM EABI":                     0x40,
+	"ARM":                          0x61,
+	"Standalone":                   0xFF,
 }
 
 // OSABIDecode map of OSABI to friendly string
@@ -78,6 +83,9 @@ var OSABIDecode = map[uint8]string{
 	0x10: "Fenix OS",
 	0x11: "CloudABI",
 	0x12: "Stratus Technologies OpenVOS",
+	0x40: "ARM EABI",
+	0x61: "ARM",
+	0xFF: "Standalone",
 }
 
 var etypeEncode = map[string]uint16{
@@ -105,101 +113,460 @@ var etypeDecode = map[uint16]string{
 }
 
 var emachineEncode = map[string]uint16{
-	"None":    0x00,
-	"SPARC":   0x02,
-	"x86":     0x03,
-	"MIPS":    0x08,
-	"PowerPC": 0x14,
-	"S390":    0x16,
-	"ARM":     0x28,
-	"SuperH":  0x2A,
-	"IA-64":   0x32,
-	"amd64":   0x3E,
-	"AArch64": 0xB7,
-	"RISC-V	": 0xF3,
+	"None":                                   0x00,
+	"AT&T WE 32100":                          0x01,
+	"SPARC":                                  0x02,
+	"x86":                                    0x03,
+	"Motorola 68000":                         0x04,
+	"Motorola 88000":                         0x05,
+	"Intel MCU":                              0x06,
+	"Intel 80860":                            0x07,
+	"MIPS":                                   0x08,
+	"IBM System/370":                         0x09,
+	"MIPS RS3000 LE":                         0x0A,
+	"HP PA-RISC":                             0x0F,
+	"Fujitsu VPP500":                         0x11,
+	"SPARC32PLUS":                            0x12,
+	"Intel 80960":                            0x13,
+	"PowerPC":                                0x14,
+	"PowerPC64":                              0x15,
+	"S390":                                   0x16,
+	"IBM SPU/SPC":                            0x17,
+	"NEC V800":                               0x24,
+	"Fujitsu FR20":                           0x25,
+	"TRW RH-32":                              0x26,
+	"Motorola RCE":                           0x27,
+	"ARM":                                    0x28,
+	"Digital Alpha":                          0x29,
+	"SuperH":                                 0x2A,
+	"SPARC v9":                               0x2B,
+	"Siemens TriCore":                        0x2C,
+	"Argonaut RISC Core":                     0x2D,
+	"Hitachi H8/300":                         0x2E,
+	"Hitachi H8/300H":                        0x2F,
+	"Hitachi H8S":                            0x30,
+	"Hitachi H8/500":                         0x31,
+	"IA-64":                                  0x32,
+	"Stanford MIPS-X":                        0x33,
+	"Motorola ColdFire":                      0x34,
+	"Motorola M68HC12":                       0x35,
+	"Fujitsu MMA":                            0x36,
+	"Siemens PCP":                            0x37,
+	"Sony nCPU":                              0x38,
+	"Denso NDR1":                             0x39,
+	"Motorola StarCore":                      0x3A,
+	"Toyota ME16":                            0x3B,
+	"STMicroelectronics ST100":               0x3C,
+	"Advanced Logic TinyJ":                   0x3D,
+	"amd64":                                  0x3E,
+	"Sony DSP":                               0x3F,
+	"DEC PDP-10":                             0x40,
+	"DEC PDP-11":                             0x41,
+	"Siemens FX66":                           0x42,
+	"STMicroelectronics ST9+":                0x43,
+	"STMicroelectronics ST7":                 0x44,
+	"Motorola MC68HC16":                      0x45,
+	"Motorola MC68HC11":                      0x46,
+	"Motorola MC68HC08":                      0x47,
+	"Motorola MC68HC05":                      0x48,
+	"Silicon Graphics SVx":                   0x49,
+	"STMicroelectronics ST19":                0x4A,
+	"Digital VAX":                            0x4B,
+	"Axis CRIS":                              0x4C,
+	"Infineon JAVELIN":                       0x4D,
+	"Element 14 FirePath":                    0x4E,
+	"LSI ZSP":                                0x4F,
+	"Donald Knuth MMIX":                      0x50,
+	"Harvard HUANY":                          0x51,
+	"SiTera Prism":                           0x52,
+	"Atmel AVR":                              0x53,
+	"Fujitsu FR30":                           0x54,
+	"Mitsubishi D10V":                        0x55,
+	"Mitsubishi D30V":                        0x56,
+	"NEC V850":                               0x57,
+	"Mitsubishi M32R":                        0x58,
+	"Matsushita MN10300":                     0x59,
+	"Matsushita MN10200":                     0x5A,
+	"picoJava":                               0x5B,
+	"OpenRISC":                               0x5C,
+	"ARC Compact":                            0x5D,
+	"Tensilica Xtensa":                       0x5E,
+	"Alphamosaic VideoCore":                  0x5F,
+	"Thompson GPP":                           0x60,
+	"National Semiconductor 32000":           0x61,
+	"Tenor TPC":                              0x62,
+	"Trebia SNP 1000":                        0x63,
+	"STMicroelectronics ST200":               0x64,
+	"Ubicom IP2K":                            0x65,
+	"MAX":                                    0x66,
+	"National Semiconductor CompactRISC":     0x67,
+	"Fujitsu F2MC16":                         0x68,
+	"TI MSP430":                              0x69,
+	"Analog Devices Blackfin":                0x6A,
+	"Seiko Epson S1C33":                      0x6B,
+	"Sharp embedded":                         0x6C,
+	"Arca RISC":                              0x6D,
+	"PKU-Unity UniCore":                      0x6E,
+	"eXcess":                                 0x6F,
+	"Icera Deep Execution Processor":         0x70,
+	"Altera Nios II":                         0x71,
+	"National Semiconductor CompactRISC CRX": 0x72,
+	"Motorola XGATE":                         0x73,
+	"Infineon C16x/XC16x":                    0x74,
+	"Renesas M16C":                           0x75,
+	"Microchip dsPIC30F":                     0x76,
+	"Freescale Communication Engine RISC":    0x77,
+	"Renesas M32C":                           0x78,
+	"Altium TSK3000":                         0x83,
+	"Freescale RS08":                         0x84,
+	"Analog Devices SHARC":                   0x85,
+	"Cyan Technology eCOG2":                  0x86,
+	"Sunplus S+core7 RISC":                   0x87,
+	"New Japan Radio 24-bit DSP":             0x88,
+	"Broadcom VideoCore III":                 0x89,
+	"Lattice Mico32":                         0x8A,
+	"Seiko Epson C17":                        0x8B,
+	"TI TMS320C6000":                         0x8C,
+	"TI TMS320C2000":                         0x8D,
+	"TI TMS320C55x":                          0x8E,
+	"TI Application Specific RISC":           0x8F,
+	"TI Programmable Realtime Unit":          0x90,
+	"STMicroelectronics 64-bit VLIW DSP":     0xA0,
+	"Cypress M8C":                            0xA1,
+	"Renesas R32C":                           0xA2,
+	"NXP TriMedia":                           0xA3,
+	"Qualcomm Hexagon":                       0xA4,
+	"Intel 8051":                             0xA5,
+	"STMicroelectronics STxP7x":              0xA6,
+	"Andes NDS32":                            0xA7,
+	"Cyan Technology eCOG1X":                 0xA8,
+	"Dallas Semiconductor MAXQ30":            0xA9,
+	"New Japan Radio 16-bit DSP":             0xAA,
+	"M2000 Reconfigurable RISC":              0xAB,
+	"Cray NV2":                               0xAC,
+	"Renesas RX":                             0xAD,
+	"Imagination Technologies META":          0xAE,
+	"MCST Elbrus":                            0xAF,
+	"Cyan Technology eCOG16":                 0xB0,
+	"National Semiconductor CompactRISC CR16": 0xB1,
+	"Freescale Extended Time Processing Unit": 0xB2,
+	"Infineon SLE9X":                        0xB3,
+	"Intel L10M":                            0xB4,
+	"Intel K10M":                            0xB5,
+	"AArch64":                               0xB7,
+	"Atmel AVR32":                           0xB9,
+	"STMicroelectronics STM8":               0xBA,
+	"Tilera TILE64":                         0xBB,
+	"Tilera TILEPro":                        0xBC,
+	"Xilinx MicroBlaze":                     0xBD,
+	"NVIDIA CUDA":                           0xBE,
+	"Tilera TILE-Gx":                        0xBF,
+	"CloudShield":                           0xC0,
+	"KIPO-KAIST Core-A 1st gen":             0xC1,
+	"KIPO-KAIST Core-A 2nd gen":             0xC2,
+	"Synopsys ARCv2":                        0xC3,
+	"Open8":                                 0xC4,
+	"Renesas RL78":                          0xC5,
+	"Broadcom VideoCore V":                  0xC6,
+	"Renesas 78KOR":                         0xC7,
+	"Freescale 56800EX":                     0xC8,
+	"Beyond BA1":                            0xC9,
+	"Beyond BA2":                            0xCA,
+	"XMOS xCORE":                            0xCB,
+	"Microchip PIC":                         0xCC,
+	"KM211 KM32":                            0xD2,
+	"KM211 KMX32":                           0xD3,
+	"KM211 KMX16":                           0xD4,
+	"KM211 KMX8":                            0xD5,
+	"KM211 KVARC":                           0xD6,
+	"Paneve CDP":                            0xD7,
+	"Cognitive Smart Memory Processor":      0xD8,
+	"Bluechip CoolEngine":                   0xD9,
+	"Nanoradio Optimized RISC":              0xDA,
+	"CSR Kalimba":                           0xDB,
+	"Zilog Z80":                             0xDC,
+	"Controls and Data Services VISIUMcore": 0xDD,
+	"FTDI FT32":                             0xDE,
+	"Moxie":                                 0xDF,
+	"AMD GPU":                               0xE0,
+	"RISC-V":                                0xF3,
+	"Linux BPF":                             0xF7,
+	"C-SKY":                                 0xFC,
+	"LoongArch":                             0x102,
 }
 
 var emachineDecode = map[uint16]string{
-	0x00: "None",
-	0x02: "SPARC",
-	0x03: "x86",
-	0x08: "MIPS",
-	0x14: "PowerPC",
-	0x16: "S390",
-	0x28: "ARM",
-	0x2A: "SuperH",
-	0x32: "IA-64",
-	0x3E: "amd64",
-	0xB7: "AArch64",
-	0xF3: "RISC-V",
+	0x00:  "None",
+	0x01:  "AT&T WE 32100",
+	0x02:  "SPARC",
+	0x03:  "x86",
+	0x04:  "Motorola 68000",
+	0x05:  "Motorola 88000",
+	0x06:  "Intel MCU",
+	0x07:  "Intel 80860",
+	0x08:  "MIPS",
+	0x09:  "IBM System/370",
+	0x0A:  "MIPS RS3000 LE",
+	0x0F:  "HP PA-RISC",
+	0x11:  "Fujitsu VPP500",
+	0x12:  "SPARC32PLUS",
+	0x13:  "Intel 80960",
+	0x14:  "PowerPC",
+	0x15:  "PowerPC64",
+	0x16:  "S390",
+	0x17:  "IBM SPU/SPC",
+	0x24:  "NEC V800",
+	0x25:  "Fujitsu FR20",
+	0x26:  "TRW RH-32",
+	0x27:  "Motorola RCE",
+	0x28:  "ARM",
+	0x29:  "Digital Alpha",
+	0x2A:  "SuperH",
+	0x2B:  "SPARC v9",
+	0x2C:  "Siemens TriCore",
+	0x2D:  "Argonaut RISC Core",
+	0x2E:  "Hitachi H8/300",
+	0x2F:  "Hitachi H8/300H",
+	0x30:  "Hitachi H8S",
+	0x31:  "Hitachi H8/500",
+	0x32:  "IA-64",
+	0x33:  "Stanford MIPS-X",
+	0x34:  "Motorola ColdFire",
+	0x35:  "Motorola M68HC12",
+	0x36:  "Fujitsu MMA",
+	0x37:  "Siemens PCP",
+	0x38:  "Sony nCPU",
+	0x39:  "Denso NDR1",
+	0x3A:  "Motorola StarCore",
+	0x3B:  "Toyota ME16",
+	0x3C:  "STMicroelectronics ST100",
+	0x3D:  "Advanced Logic TinyJ",
+	0x3E:  "amd64",
+	0x3F:  "Sony DSP",
+	0x40:  "DEC PDP-10",
+	0x41:  "DEC PDP-11",
+	0x42:  "Siemens FX66",
+	0x43:  "STMicroelectronics ST9+",
+	0x44:  "STMicroelectronics ST7",
+	0x45:  "Motorola MC68HC16",
+	0x46:  "Motorola MC68HC11",
+	0x47:  "Motorola MC68HC08",
+	0x48:  "Motorola MC68HC05",
+	0x49:  "Silicon Graphics SVx",
+	0x4A:  "STMicroelectronics ST19",
+	0x4B:  "Digital VAX",
+	0x4C:  "Axis CRIS",
+	0x4D:  "Infineon JAVELIN",
+	0x4E:  "Element 14 FirePath",
+	0x4F:  "LSI ZSP",
+	0x50:  "Donald Knuth MMIX",
+	0x51:  "Harvard HUANY",
+	0x52:  "SiTera Prism",
+	0x53:  "Atmel AVR",
+	0x54:  "Fujitsu FR30",
+	0x55:  "Mitsubishi D10V",
+	0x56:  "Mitsubishi D30V",
+	0x57:  "NEC V850",
+	0x58:  "Mitsubishi M32R",
+	0x59:  "Matsushita MN10300",
+	0x5A:  "Matsushita MN10200",
+	0x5B:  "picoJava",
+	0x5C:  "OpenRISC",
+	0x5D:  "ARC Compact",
+	0x5E:  "Tensilica Xtensa",
+	0x5F:  "Alphamosaic VideoCore",
+	0x60:  "Thompson GPP",
+	0x61:  "National Semiconductor 32000",
+	0x62:  "Tenor TPC",
+	0x63:  "Trebia SNP 1000",
+	0x64:  "STMicroelectronics ST200",
+	0x65:  "Ubicom IP2K",
+	0x66:  "MAX",
+	0x67:  "National Semiconductor CompactRISC",
+	0x68:  "Fujitsu F2MC16",
+	0x69:  "TI MSP430",
+	0x6A:  "Analog Devices Blackfin",
+	0x6B:  "Seiko Epson S1C33",
+	0x6C:  "Sharp embedded",
+	0x6D:  "Arca RISC",
+	0x6E:  "PKU-Unity UniCore",
+	0x6F:  "eXcess",
+	0x70:  "Icera Deep Execution Processor",
+	0x71:  "Altera Nios II",
+	0x72:  "National Semiconductor CompactRISC CRX",
+	0x73:  "Motorola XGATE",
+	0x74:  "Infineon C16x/XC16x",
+	0x75:  "Renesas M16C",
+	0x76:  "Microchip dsPIC30F",
+	0x77:  "Freescale Communication Engine RISC",
+	0x78:  "Renesas M32C",
+	0x83:  "Altium TSK3000",
+	0x84:  "Freescale RS08",
+	0x85:  "Analog Devices SHARC",
+	0x86:  "Cyan Technology eCOG2",
+	0x87:  "Sunplus S+core7 RISC",
+	0x88:  "New Japan Radio 24-bit DSP",
+	0x89:  "Broadcom VideoCore III",
+	0x8A:  "Lattice Mico32",
+	0x8B:  "Seiko Epson C17",
+	0x8C:  "TI TMS320C6000",
+	0x8D:  "TI TMS320C2000",
+	0x8E:  "TI TMS320C55x",
+	0x8F:  "TI Application Specific RISC",
+	0x90:  "TI Programmable Realtime Unit",
+	0xA0:  "STMicroelectronics 64-bit VLIW DSP",
+	0xA1:  "Cypress M8C",
+	0xA2:  "Renesas R32C",
+	0xA3:  "NXP TriMedia",
+	0xA4:  "Qualcomm Hexagon",
+	0xA5:  "Intel 8051",
+	0xA6:  "STMicroelectronics STxP7x",
+	0xA7:  "Andes NDS32",
+	0xA8:  "Cyan Technology eCOG1X",
+	0xA9:  "Dallas Semiconductor MAXQ30",
+	0xAA:  "New Japan Radio 16-bit DSP",
+	0xAB:  "M2000 Reconfigurable RISC",
+	0xAC:  "Cray NV2",
+	0xAD:  "Renesas RX",
+	0xAE:  "Imagination Technologies META",
+	0xAF:  "MCST Elbrus",
+	0xB0:  "Cyan Technology eCOG16",
+	0xB1:  "National Semiconductor CompactRISC CR16",
+	0xB2:  "Freescale Extended Time Processing Unit",
+	0xB3:  "Infineon SLE9X",
+	0xB4:  "Intel L10M",
+	0xB5:  "Intel K10M",
+	0xB7:  "AArch64",
+	0xB9:  "Atmel AVR32",
+	0xBA:  "STMicroelectronics STM8",
+	0xBB:  "Tilera TILE64",
+	0xBC:  "Tilera TILEPro",
+	0xBD:  "Xilinx MicroBlaze",
+	0xBE:  "NVIDIA CUDA",
+	0xBF:  "Tilera TILE-Gx",
+	0xC0:  "CloudShield",
+	0xC1:  "KIPO-KAIST Core-A 1st gen",
+	0xC2:  "KIPO-KAIST Core-A 2nd gen",
+	0xC3:  "Synopsys ARCv2",
+	0xC4:  "Open8",
+	0xC5:  "Renesas RL78",
+	0xC6:  "Broadcom VideoCore V",
+	0xC7:  "Renesas 78KOR",
+	0xC8:  "Freescale 56800EX",
+	0xC9:  "Beyond BA1",
+	0xCA:  "Beyond BA2",
+	0xCB:  "XMOS xCORE",
+	0xCC:  "Microchip PIC",
+	0xD2:  "KM211 KM32",
+	0xD3:  "KM211 KMX32",
+	0xD4:  "KM211 KMX16",
+	0xD5:  "KM211 KMX8",
+	0xD6:  "KM211 KVARC",
+	0xD7:  "Paneve CDP",
+	0xD8:  "Cognitive Smart Memory Processor",
+	0xD9:  "Bluechip CoolEngine",
+	0xDA:  "Nanoradio Optimized RISC",
+	0xDB:  "CSR Kalimba",
+	0xDC:  "Zilog Z80",
+	0xDD:  "Controls and Data Services VISIUMcore",
+	0xDE:  "FTDI FT32",
+	0xDF:  "Moxie",
+	0xE0:  "AMD GPU",
+	0xF3:  "RISC-V",
+	0xF7:  "Linux BPF",
+	0xFC:  "C-SKY",
+	0x102: "LoongArch",
+}
+
+// MachineName returns the friendly name of an EMachine value, or the value in
+// hex when it is not a known EM_* constant
+func MachineName(machine uint16) string {
+	return decodeOrHex(emachineDecode[machine], uint64(machine))
+}
+
+// decodeOrHex returns name, or val in hex when a decode map had no name for it
+func decodeOrHex(name string, val uint64) string {
+	if name == "" {
+		return fmt.Sprintf("0x%X", val)
+	}
+	return name
 }
 
 // FileHeader64 64 bit ELF header
 type FileHeader64 struct {
-	EIMAG        uint32 /* Magic number, always 0x7F454C46 ("\x7fELF") */
-	EICLASS      uint8  /* ELF Class 1 = 32bit, 2 = 64bit */
-	EIDATA       uint8  /* Endianess 1 = little, 2 = big */
-	EIVERSION    uint8  /* Always 1 */
-	EIOSABI      uint8  /* Usually just set to 0 no matter what */
-	EIABIVERSION uint8  /* Usually 0, sometimes not zero if EI_OSABI == 3 */
-	EType        uint16 /* Identifies object file type - see etypeEncode */
-	EMachine     uint16 /* Specifies ISA - see emachineEncode */
-	EVersion     uint32 /* Set to 1 */
-	EEntry       uint64 /* Addr of entry point of process */
-	EPhoff       uint64 /* Pos of the program header table - usually 0x40 */
-	EShoff       uint64 /* Pos of section header table */
-	EFlags       uint32 /* Depends on arch */
-	EEhsize      uint16 /* Size of this header, usually 64 bytes */
-	EPhentsize   uint16 /* Size of a program header table entry */
-	EPhnum       uint16 /* Number of entries in program header table */
-	EShentsize   uint16 /* Size of a section header table entry */
-	EShnum       uint16 /* Number of entries in section header table */
-	EShstrndx    uint16 /* Index of section header table entry containing section names */
-	OSABI        string /* Friendly name of EI_OSABI */
-	Type         string /* Friendly name of E_type */
-	TypeDesc     string /* Description of E_type */
-	Machine      string /* Friendly name of E_machine */
-	Endian       string /* Friendly name of EI_data */
-	Arch         string /* Friendly name of EI_class */
+	EIMAG        uint32   /* Magic number, always 0x7F454C46 ("\x7fELF") */
+	EICLASS      uint8    /* ELF Class 1 = 32bit, 2 = 64bit */
+	EIDATA       uint8    /* Endianess 1 = little, 2 = big */
+	EIVERSION    uint8    /* Always 1 */
+	EIOSABI      uint8    /* Usually just set to 0 no matter what */
+	EIABIVERSION uint8    /* Usually 0, sometimes not zero if EI_OSABI == 3 */
+	EType        uint16   /* Identifies object file type - see etypeEncode */
+	EMachine     uint16   /* Specifies ISA - see emachineEncode */
+	EVersion     uint32   /* Set to 1 */
+	EEntry       uint64   /* Addr of entry point of process */
+	EPhoff       uint64   /* Pos of the program header table - usually 0x40 */
+	EShoff       uint64   /* Pos of section header table */
+	EFlags       uint32   /* Depends on arch */
+	EEhsize      uint16   /* Size of this header, usually 64 bytes */
+	EPhentsize   uint16   /* Size of a program header table entry */
+	EPhnum       uint16   /* Number of entries in program header table */
+	EShentsize   uint16   /* Size of a section header table entry */
+	EShnum       uint16   /* Number of entries in section header table */
+	EShstrndx    uint16   /* Index of section header table entry containing section names */
+	OSABI        string   /* Friendly name of EI_OSABI */
+	Type         string   /* Friendly name of E_type */
+	TypeDesc     string   /* Description of E_type */
+	Machine      string   /* Friendly name of E_machine */
+	Endian       string   /* Friendly name of EI_data */
+	Arch         string   /* Friendly name of EI_class */
+	Flags        []string /* Friendly names of the architecture specific EFlags, see EFlagsNames */
 }
 
 // FileHeader32 32 bit ELF header
 type FileHeader32 struct {
-	EIMAG        uint32 /* Magic number, always 0x7F454C46 ("\x7fELF") */
-	EICLASS      uint8  /* ELF Class 1 = 32bit, 2 = 64bit */
-	EIDATA       uint8  /* Endianess 1 = little, 2 = big */
-	EIVERSION    uint8  /* Always 1 */
-	EIOSABI      uint8  /* Usually just set to 0 no matter what */
-	EIABIVERSION uint8  /* Usually 0, sometimes not zero if EI_OSABI == 3 */
-	EType        uint16 /* Identifies object file type - see etypeEncode */
-	EMachine     uint16 /* Specifies ISA - see emachineEncode */
-	EVersion     uint32 /* Set to 1 */
-	EEntry       uint32 /* Addr of entry point of process */
-	EPhoff       uint32 /* Pos of the program header table - usually 0x40 */
-	EShoff       uint32 /* Pos of section header table */
-	EFlags       uint32 /* Depends on arch */
-	EEhsize      uint16 /* Size of this header, usually 64 bytes */
-	EPhentsize   uint16 /* Size of a program header table entry */
-	EPhnum       uint16 /* Number of entries in program header table */
-	EShentsize   uint16 /* Size of a section header table entry */
-	EShnum       uint16 /* Number of entries in section header table */
-	EShstrndx    uint16 /* Index of section header table entry containing section names */
-	OSABI        string /* Friendly name of EI_OSABI */
-	Type         string /* Friendly name of E_type */
-	TypeDesc     string /* Description of E_type */
-	Machine      string /* Friendly name of E_machine */
-	Endian       string /* Friendly name of EI_data */
-	Arch         string /* Friendly name of EI_class */
+	EIMAG        uint32   /* Magic number, always 0x7F454C46 ("\x7fELF") */
+	EICLASS      uint8    /* ELF Class 1 = 32bit, 2 = 64bit */
+	EIDATA       uint8    /* Endianess 1 = little, 2 = big */
+	EIVERSION    uint8    /* Always 1 */
+	EIOSABI      uint8    /* Usually just set to 0 no matter what */
+	EIABIVERSION uint8    /* Usually 0, sometimes not zero if EI_OSABI == 3 */
+	EType        uint16   /* Identifies object file type - see etypeEncode */
+	EMachine     uint16   /* Specifies ISA - see emachineEncode */
+	EVersion     uint32   /* Set to 1 */
+	EEntry       uint32   /* Addr of entry point of process */
+	EPhoff       uint32   /* Pos of the program header table - usually 0x34 */
+	EShoff       uint32   /* Pos of section header table */
+	EFlags       uint32   /* Depends on arch */
+	EEhsize      uint16   /* Size of this header, usually 52 bytes */
+	EPhentsize   uint16   /* Size of a program header table entry */
+	EPhnum       uint16   /* Number of entries in program header table */
+	EShentsize   uint16   /* Size of a section header table entry */
+	EShnum       uint16   /* Number of entries in section header table */
+	EShstrndx    uint16   /* Index of section header table entry containing section names */
+	OSABI        string   /* Friendly name of EI_OSABI */
+	Type         string   /* Friendly name of E_type */
+	TypeDesc     string   /* Description of E_type */
+	Machine      string   /* Friendly name of E_machine */
+	Endian       string   /* Friendly name of EI_data */
+	Arch         string   /* Friendly name of EI_class */
+	Flags        []string /* Friendly names of the architecture specific EFlags, see EFlagsNames */
 }
 
 // FromBuffer initializes the FileHeader64 given a buffer of the size of the
 // file header
-func (h *FileHeader64) FromBuffer(buf []byte) {
+func (h *FileHeader64) FromBuffer(buf []byte) error {
+	if len(buf) < fileHeader64Size {
+		return formatErr(0, "file header", ErrNotELF, "header is only 0x%X bytes", len(buf))
+	}
 	h.EIMAG = readu32be(buf[:4], 0)
-	assert(h.EIMAG == 0x7F454C46, "Magic number missing! Not an ELF!!")
+	if h.EIMAG != elfMagic {
+		return formatErr(0, "file header", ErrNotELF, "bad magic number 0x%08X", h.EIMAG)
+	}
 
 	h.EICLASS = readu8(buf, 0x04)
-	assert(h.EICLASS == CLASS64BIT, fmt.Sprintf("Wrong class type, EI_CLASS not 64 bit, actually 0x%X", h.EICLASS))
+	if h.EICLASS != CLASS64BIT {
+		return formatErr(0x04, "file header", ErrUnsupportedClass, "EI_CLASS not 64 bit, actually 0x%X", h.EICLASS)
+	}
 
 	h.EIDATA = readu8(buf, 0x05)
 	h.EIVERSION = readu8(buf, 0x06)
@@ -234,23 +601,34 @@ func (h *FileHeader64) FromBuffer(buf []byte) {
 		h.EShentsize = readu16le(buf, 0x3a)
 		h.EShnum = readu16le(buf, 0x3c)
 		h.EShstrndx = readu16le(buf, 0x3e)
+	default:
+		return formatErr(5, "file header", nil, "unsupported EI_DATA 0x%X", h.EIDATA)
 	}
-	h.OSABI = OSABIDecode[h.EIOSABI]
-	h.Type = etypeDecode[h.EType]
+	h.OSABI = decodeOrHex(OSABIDecode[h.EIOSABI], uint64(h.EIOSABI))
+	h.Type = decodeOrHex(etypeDecode[h.EType], uint64(h.EType))
 	h.TypeDesc = typeDesc[h.Type]
-	h.Machine = emachineDecode[h.EMachine]
+	h.Machine = MachineName(h.EMachine)
 	h.Endian = DataMap[h.EIDATA]
 	h.Arch = ClassMap[h.EICLASS]
+	h.Flags = EFlagsNames(h.EMachine, h.EFlags)
+	return nil
 }
 
 // FromBuffer initializes the FileHeader32 given a buffer of the size of the
 // file header
-func (h *FileHeader32) FromBuffer(buf []byte) {
+func (h *FileHeader32) FromBuffer(buf []byte) error {
+	if len(buf) < fileHeader32Size {
+		return formatErr(0, "file header", ErrNotELF, "header is only 0x%X bytes", len(buf))
+	}
 	h.EIMAG = readu32be(buf[:4], 0)
-	assert(h.EIMAG == 0x7F454C46, "Magic number missing! Not an ELF!!")
+	if h.EIMAG != elfMagic {
+		return formatErr(0, "file header", ErrNotELF, "bad magic number 0x%08X", h.EIMAG)
+	}
 
 	h.EICLASS = readu8(buf, 0x04)
-	assert(h.EICLASS == CLASS64BIT, fmt.Sprintf("Wrong class type, EI_CLASS not 64 bit, actually 0x%X", h.EICLASS))
+	if h.EICLASS != CLASS32BIT {
+		return formatErr(0x04, "file header", ErrUnsupportedClass, "EI_CLASS not 32 bit, actually 0x%X", h.EICLASS)
+	}
 
 	h.EIDATA = readu8(buf, 0x05)
 	h.EIVERSION = readu8(buf, 0x06)
@@ -285,11 +663,46 @@ func (h *FileHeader32) FromBuffer(buf []byte) {
 		h.EShentsize = readu16le(buf, 0x2E)
 		h.EShnum = readu16le(buf, 0x30)
 		h.EShstrndx = readu16le(buf, 0x32)
+	default:
+		return formatErr(5, "file header", nil, "unsupported EI_DATA 0x%X", h.EIDATA)
 	}
-	h.OSABI = OSABIDecode[h.EIOSABI]
-	h.Type = etypeDecode[h.EType]
+	h.OSABI = decodeOrHex(OSABIDecode[h.EIOSABI], uint64(h.EIOSABI))
+	h.Type = decodeOrHex(etypeDecode[h.EType], uint64(h.EType))
 	h.TypeDesc = typeDesc[h.Type]
-	h.Machine = emachineDecode[h.EMachine]
+	h.Machine = MachineName(h.EMachine)
 	h.Endian = DataMap[h.EIDATA]
 	h.Arch = ClassMap[h.EICLASS]
+	h.Flags = EFlagsNames(h.EMachine, h.EFlags)
+	return nil
+}
+
+func (h FileHeader32) widen() FileHeader64 {
+	return FileHeader64{
+		EIMAG:        h.EIMAG,
+		EICLASS:      h.EICLASS,
+		EIDATA:       h.EIDATA,
+		EIVERSION:    h.EIVERSION,
+		EIOSABI:      h.EIOSABI,
+		EIABIVERSION: h.EIABIVERSION,
+		EType:        h.EType,
+		EMachine:     h.EMachine,
+		EVersion:     h.EVersion,
+		EEntry:       uint64(h.EEntry),
+		EPhoff:       uint64(h.EPhoff),
+		EShoff:       uint64(h.EShoff),
+		EFlags:       h.EFlags,
+		EEhsize:      h.EEhsize,
+		EPhentsize:   h.EPhentsize,
+		EPhnum:       h.EPhnum,
+		EShentsize:   h.EShentsize,
+		EShnum:       h.EShnum,
+		EShstrndx:    h.EShstrndx,
+		OSABI:        h.OSABI,
+		Type:         h.Type,
+		TypeDesc:     h.TypeDesc,
+		Machine:      h.Machine,
+		Endian:       h.Endian,
+		Arch:         h.Arch,
+		Flags:        h.Flags,
+	}
 }
@@ -2,4 +2,8 @@ module github.com/vfrazao-ns1/elf
 
 go 1.13
 
-require github.com/knightsc/gapstone v4.0.1+incompatible
+require (
+	github.com/ianlancetaylor/demangle v0.0.0-20240912202439-0a2b6291aafd
+	github.com/klauspost/compress v1.13.6
+	github.com/knightsc/gapstone v4.0.1+incompatible
+)
@@ -0,0 +1,198 @@
+package elf
+
+import (
+	"encoding/binary"
+	"strconv"
+	"strings"
+)
+
+// goBuildInfoMagic starts the .go.buildinfo section
+const goBuildInfoMagic = "\xff Go buildinf:"
+
+// GoModule a module linked into a Go binary
+type GoModule struct {
+	Path    string    /* Module path */
+	Version string    /* Module version, (devel) for the main module of a local build */
+	Sum     string    /* go.sum checksum */
+	Replace *GoModule /* Replacement module, nil when not replaced */
+}
+
+// GoBuildSetting a key=value setting recorded by the go command, e.g. GOOS=linux
+type GoBuildSetting struct {
+	Key   string
+	Value string
+}
+
+// GoBuildInfo decoded .go.buildinfo, what runtime/debug.ReadBuildInfo reports
+type GoBuildInfo struct {
+	GoVersion string           /* Version of the toolchain, e.g. go1.21.0 */
+	Path      string           /* Package path of the main package */
+	Main      GoModule         /* Module containing the main package */
+	Deps      []GoModule       /* Dependencies */
+	Settings  []GoBuildSetting /* Build settings */
+}
+
+// GoBuildInfo decodes the build information of a Go binary. Files without
+// section headers are searched for the .go.buildinfo magic
+func (e ELF64) GoBuildInfo() (*GoBuildInfo, error) {
+	return readGoBuildInfo(e)
+}
+
+// GoBuildInfo decodes the build information of a Go binary. Files without
+// section headers are searched for the .go.buildinfo magic
+func (e ELF32) GoBuildInfo() (*GoBuildInfo, error) {
+	return readGoBuildInfo(e)
+}
+
+func readGoBuildInfo(f File) (*GoBuildInfo, error) {
+	var data []byte
+	var addr uint64
+	sections := f.SectionData()
+	for i, h := range f.SectionTable() {
+		if h.SectionName == ".go.buildinfo" {
+			data, addr = sections[i].Data, h.SHAddr
+		}
+	}
+	if data == nil {
+		// The linker puts the 16 byte aligned header at the start of the
+		// writable data
+		for _, ph := range f.Segments() {
+			if ph.PType != PTypeEncode["PT_LOAD"] {
+				continue
+			}
+			for off := 0; off+32 <= len(ph.Data); off += 16 {
+				if string(ph.Data[off:off+len(goBuildInfoMagic)]) == goBuildInfoMagic {
+					data, addr = ph.Data[off:], ph.PVaddr+uint64(off)
+					break
+				}
+			}
+			if data != nil {
+				break
+			}
+		}
+	}
+	if len(data) < 32 || string(data[:len(goBuildInfoMagic)]) != goBuildInfoMagic {
+		return nil, ErrNoGoBuildInfo
+	}
+
+	ptrSize := data[14]
+	flags := data[15]
+	var version, modinfo string
+	if flags&0x2 != 0 {
+		// Go 1.18 and later store the strings inline, varint length prefixed
+		var ok bool
+		rest := data[32:]
+		if version, rest, ok = goVarintString(rest); !ok {
+			return nil, formatErr(int64(addr), ".go.buildinfo", nil, "truncated version string")
+		}
+		if modinfo, _, ok = goVarintString(rest); !ok {
+			return nil, formatErr(int64(addr), ".go.buildinfo", nil, "truncated module string")
+		}
+	} else {
+		// Older binaries point at Go string headers in the data segment
+		endianess := uint8(litteEndian)
+		if flags&0x1 != 0 {
+			endianess = bigEndian
+		}
+		space := newAddressSpace(f.Segments(), f.Class(), endianess)
+		var err error
+		if version, err = goStringAt(space, data[16:], ptrSize, endianess); err != nil {
+			return nil, err
+		}
+		if modinfo, err = goStringAt(space, data[16+ptrSize:], ptrSize, endianess); err != nil {
+			return nil, err
+		}
+	}
+	if version == "" {
+		return nil, ErrNoGoBuildInfo
+	}
+
+	// The module information is wrapped in 16 byte sentinels
+	if len(modinfo) >= 33 && modinfo[len(modinfo)-17] == '\n' {
+		modinfo = modinfo[16 : len(modinfo)-16]
+	} else {
+		modinfo = ""
+	}
+	info := parseGoModInfo(modinfo)
+	info.GoVersion = version
+	return info, nil
+}
+
+func goVarintString(buf []byte) (string, []byte, bool) {
+	n, size := binary.Uvarint(buf)
+	if size <= 0 || n > uint64(len(buf)-size) {
+		return "", nil, false
+	}
+	return string(buf[size : size+int(n)]), buf[size+int(n):], true
+}
+
+// goStringAt reads the string of the Go string header that ptr points to
+func goStringAt(space *AddressSpace, ptr []byte, ptrSize uint8, endianess uint8) (string, error) {
+	b := &dwarfBuf{data: ptr, endianess: endianess, name: ".go.buildinfo"}
+	hdr := make([]byte, 2*int(ptrSize))
+	if _, err := space.ReadAt(hdr, int64(b.addr(ptrSize))); err != nil {
+		return "", err
+	}
+	if b.err != nil {
+		return "", b.err
+	}
+	b = &dwarfBuf{data: hdr, endianess: endianess, name: "Go string"}
+	strAddr := b.addr(ptrSize)
+	strLen := b.addr(ptrSize)
+	if strLen > 1<<24 {
+		return "", formatErr(int64(strAddr), "Go string", nil, "length 0x%X implausible", strLen)
+	}
+	str := make([]byte, strLen)
+	if _, err := space.ReadAt(str, int64(strAddr)); err != nil {
+		return "", err
+	}
+	return string(str), nil
+}
+
+// parseGoModInfo parses the tab separated lines runtime/debug.BuildInfo.String produces
+func parseGoModInfo(modinfo string) *GoBuildInfo {
+	info := &GoBuildInfo{Deps: make([]GoModule, 0), Settings: make([]GoBuildSetting, 0)}
+	module := func(fields []string) GoModule {
+		m := GoModule{Path: fields[0]}
+		if len(fields) > 1 {
+			m.Version = fields[1]
+		}
+		if len(fields) > 2 {
+			m.Sum = fields[2]
+		}
+		return m
+	}
+	var last *GoModule
+	for _, line := range strings.Split(modinfo, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) < 2 {
+			continue
+		}
+		switch fields[0] {
+		case "path":
+			info.Path = fields[1]
+		case "mod":
+			info.Main = module(fields[1:])
+			last = &info.Main
+		case "dep":
+			info.Deps = append(info.Deps, module(fields[1:]))
+			last = &info.Deps[len(info.Deps)-1]
+		case "=>":
+			if last != nil {
+				replace := module(fields[1:])
+				last.Replace = &replace
+			}
+		case "build":
+			setting := GoBuildSetting{Key: fields[1]}
+			if i := strings.IndexByte(fields[1], '='); i >= 0 {
+				setting.Key, setting.Value = fields[1][:i], fields[1][i+1:]
+			}
+			// Values containing spaces, tabs or quotes are quoted
+			if unquoted, err := strconv.Unquote(setting.Value); err == nil {
+				setting.Value = unquoted
+			}
+			info.Settings = append(info.Settings, setting)
+		}
+	}
+	return info
+}
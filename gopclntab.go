@@ -0,0 +1,445 @@
+package elf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// GoPCLNTabVersionDecode maps the .gopclntab magic number to the Go release that introduced the format
+var GoPCLNTabVersionDecode = map[uint32]string{
+	0xfffffffb: "go1.2",
+	0xfffffffa: "go1.16",
+	0xfffffff0: "go1.18",
+	0xfffffff1: "go1.20",
+}
+
+// GoFunc a function of the Go runtime's function table
+type GoFunc struct {
+	Name  string /* Fully qualified name, e.g. main.main */
+	Entry uint64 /* First address of the function */
+	End   uint64 /* One past the last address, the entry of the next function */
+
+	data []byte /* The _func structure */
+}
+
+// GoLine a run of instructions generated from one source line
+type GoLine struct {
+	PC   uint64 /* First address of the run */
+	File string
+	Line int
+}
+
+// GoPCLNTab decoded .gopclntab, the function and line tables the Go runtime
+// uses for tracebacks. It survives stripping of .symtab and DWARF
+type GoPCLNTab struct {
+	Version   string /* Format of the table, see GoPCLNTabVersionDecode */
+	Quantum   uint8  /* Instruction size quantum, pc deltas are multiples of it */
+	PtrSize   uint8
+	TextStart uint64 /* Base of the function entry offsets, go1.18 and later */
+	Funcs     []GoFunc
+
+	data      []byte
+	magic     uint32
+	endianess uint8
+	funcnames []byte
+	cutab     []byte
+	filetab   []byte
+	pctab     []byte
+	nfiletab  uint32
+}
+
+// GoPCLNTab decodes the Go function and line tables. Files without section
+// headers are searched for the table header
+func (e ELF64) GoPCLNTab() (*GoPCLNTab, error) {
+	return readGoPCLNTab(e)
+}
+
+// GoPCLNTab decodes the Go function and line tables. Files without section
+// headers are searched for the table header
+func (e ELF32) GoPCLNTab() (*GoPCLNTab, error) {
+	return readGoPCLNTab(e)
+}
+
+func readGoPCLNTab(f File) (*GoPCLNTab, error) {
+	endianess := f.Header().EIDATA
+	var textAddr, tabAddr uint64
+	var data []byte
+	sections := f.SectionData()
+	for i, h := range f.SectionTable() {
+		switch h.SectionName {
+		case ".gopclntab":
+			data, tabAddr = sections[i].Data, h.SHAddr
+		case ".text":
+			textAddr = h.SHAddr
+		case ".data.rel.ro.gopclntab": /* PIE and shared builds with external linking */
+			if data == nil {
+				data, tabAddr = sections[i].Data, h.SHAddr
+			}
+		}
+	}
+	if data == nil {
+		for _, ph := range f.Segments() {
+			if ph.PType != PTypeEncode["PT_LOAD"] {
+				continue
+			}
+			if off := findGoPCLNTab(ph.Data, endianess); off >= 0 {
+				data, tabAddr = ph.Data[off:], ph.PVaddr+uint64(off)
+				break
+			}
+		}
+	}
+	if data == nil {
+		return nil, ErrNoGoPCLNTab
+	}
+
+	t, err := newGoPCLNTab(data, endianess)
+	if err != nil {
+		return nil, err
+	}
+	if t.TextStart == 0 && t.magic != 0xfffffffb && t.magic != 0xfffffffa {
+		// Recent linkers leave the field zero, the runtime takes it from
+		// the module data instead
+		t.TextStart = textAddr
+		if t.TextStart == 0 {
+			t.TextStart = goModuleText(f, tabAddr, t)
+		}
+	}
+	if err := t.readFuncs(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// goModuleText finds runtime.firstmoduledata, which starts with a pointer to
+// the table followed by the funcnametab slice, and returns its text field
+func goModuleText(f File, tabAddr uint64, t *GoPCLNTab) uint64 {
+	funcnames := tabAddr + uint64(len(t.data)-len(t.funcnames))
+	size := int(t.PtrSize)
+	for _, ph := range f.Segments() {
+		if ph.PType != PTypeEncode["PT_LOAD"] || ph.PFlags&0x2 == 0 {
+			continue
+		}
+		b := &dwarfBuf{data: ph.Data, endianess: t.endianess}
+		for off := 0; off+23*size <= len(ph.Data); off += size {
+			b.off = off
+			if b.addr(t.PtrSize) != tabAddr || b.addr(t.PtrSize) != funcnames {
+				continue
+			}
+			// pcHeader, five slices and ftab, findfunctab, minpc, maxpc, then text
+			b.off = off + 22*size
+			return b.addr(t.PtrSize)
+		}
+	}
+	return 0
+}
+
+// findGoPCLNTab searches a segment for a plausible table header and returns
+// its offset, or -1
+func findGoPCLNTab(data []byte, endianess uint8) int {
+	u32 := readu32Func(endianess)
+	for off := 0; off+16 <= len(data); off += 4 {
+		if _, ok := GoPCLNTabVersionDecode[u32(data, off)]; !ok {
+			continue
+		}
+		if data[off+4] != 0 || data[off+5] != 0 {
+			continue
+		}
+		if q := data[off+6]; q != 1 && q != 2 && q != 4 {
+			continue
+		}
+		if p := data[off+7]; p != 4 && p != 8 {
+			continue
+		}
+		if t, err := newGoPCLNTab(data[off:], endianess); err == nil && t.readFuncs() == nil && len(t.Funcs) != 0 {
+			return off
+		}
+	}
+	return -1
+}
+
+func newGoPCLNTab(data []byte, endianess uint8) (*GoPCLNTab, error) {
+	if len(data) < 16 {
+		return nil, formatErr(0, ".gopclntab", nil, "header truncated")
+	}
+	t := &GoPCLNTab{data: data, endianess: endianess}
+	t.magic = readu32Func(endianess)(data, 0)
+	t.Version = GoPCLNTabVersionDecode[t.magic]
+	if t.Version == "" {
+		return nil, formatErr(0, ".gopclntab", nil, "unknown magic 0x%08X", t.magic)
+	}
+	t.Quantum = data[6]
+	t.PtrSize = data[7]
+	if t.PtrSize != 4 && t.PtrSize != 8 {
+		return nil, formatErr(7, ".gopclntab", nil, "pointer size %d", t.PtrSize)
+	}
+
+	// Header words after the magic, all pointer sized
+	word := func(i int) uint64 {
+		b := &dwarfBuf{data: data, endianess: endianess, off: 8 + i*int(t.PtrSize)}
+		return b.addr(t.PtrSize)
+	}
+	table := func(i int) ([]byte, error) {
+		off := word(i)
+		if off > uint64(len(data)) {
+			return nil, formatErr(int64(8+i*int(t.PtrSize)), ".gopclntab", nil, "table offset 0x%X past end", off)
+		}
+		return data[off:], nil
+	}
+	if len(data) < 8+8*int(t.PtrSize) {
+		return nil, formatErr(0, ".gopclntab", nil, "header truncated")
+	}
+
+	var err error
+	switch t.magic {
+	case 0xfffffffb:
+		// Everything is relative to the start of the table
+		t.funcnames, t.pctab = data, data
+	case 0xfffffffa:
+		t.nfiletab = uint32(word(1))
+		if t.funcnames, err = table(2); err != nil {
+			return nil, err
+		}
+		if t.cutab, err = table(3); err != nil {
+			return nil, err
+		}
+		if t.filetab, err = table(4); err != nil {
+			return nil, err
+		}
+		if t.pctab, err = table(5); err != nil {
+			return nil, err
+		}
+	default:
+		t.nfiletab = uint32(word(1))
+		t.TextStart = word(2)
+		if t.funcnames, err = table(3); err != nil {
+			return nil, err
+		}
+		if t.cutab, err = table(4); err != nil {
+			return nil, err
+		}
+		if t.filetab, err = table(5); err != nil {
+			return nil, err
+		}
+		if t.pctab, err = table(6); err != nil {
+			return nil, err
+		}
+	}
+	return t, nil
+}
+
+// readFuncs decodes the function table, pairs of entry pc and _func offset
+func (t *GoPCLNTab) readFuncs() error {
+	nfunctab := (&dwarfBuf{data: t.data, endianess: t.endianess, off: 8}).addr(t.PtrSize)
+	var functab []byte
+	fieldSize := int(t.PtrSize)
+	switch t.magic {
+	case 0xfffffffb:
+		functab = t.data[8+int(t.PtrSize):]
+	case 0xfffffffa:
+		off := (&dwarfBuf{data: t.data, endianess: t.endianess, off: 8 + 6*int(t.PtrSize)}).addr(t.PtrSize)
+		if off > uint64(len(t.data)) {
+			return formatErr(0, ".gopclntab", nil, "function table offset 0x%X past end", off)
+		}
+		functab = t.data[off:]
+	default:
+		off := (&dwarfBuf{data: t.data, endianess: t.endianess, off: 8 + 7*int(t.PtrSize)}).addr(t.PtrSize)
+		if off > uint64(len(t.data)) {
+			return formatErr(0, ".gopclntab", nil, "function table offset 0x%X past end", off)
+		}
+		functab = t.data[off:]
+		fieldSize = 4
+	}
+	if nfunctab > uint64(len(functab)/(2*fieldSize)) {
+		return formatErr(8, ".gopclntab", nil, "0x%X functions past end of table", nfunctab)
+	}
+	// The _func offsets of go1.2 are relative to the table, later ones to functab
+	funcdata := functab
+	if t.magic == 0xfffffffb {
+		funcdata = t.data
+	}
+
+	b := &dwarfBuf{data: functab, endianess: t.endianess, name: ".gopclntab"}
+	field := func() uint64 {
+		if fieldSize == 4 {
+			return uint64(b.u32())
+		}
+		return b.addr(t.PtrSize)
+	}
+	t.Funcs = make([]GoFunc, nfunctab)
+	for i := range t.Funcs {
+		entry := field()
+		funcOff := field()
+		if t.magic != 0xfffffffb && t.magic != 0xfffffffa {
+			entry += t.TextStart
+		}
+		if funcOff >= uint64(len(funcdata)) {
+			return formatErr(0, ".gopclntab", nil, "_func offset 0x%X past end", funcOff)
+		}
+		t.Funcs[i].Entry = entry
+		t.Funcs[i].data = funcdata[funcOff:]
+		nameOff := t.field(t.Funcs[i].data, 1)
+		t.Funcs[i].Name, _ = readCStr(t.funcnames, int(nameOff))
+	}
+	// The table ends with the pc past the last function
+	end := field()
+	if t.magic != 0xfffffffb && t.magic != 0xfffffffa {
+		end += t.TextStart
+	}
+	for i := range t.Funcs {
+		if i+1 < len(t.Funcs) {
+			t.Funcs[i].End = t.Funcs[i+1].Entry
+		} else {
+			t.Funcs[i].End = end
+		}
+	}
+	return b.err
+}
+
+// field returns the nth 32 bit field of a _func, after the entry pc
+func (t *GoPCLNTab) field(fn []byte, n int) uint32 {
+	off := int(t.PtrSize)
+	if t.magic != 0xfffffffb && t.magic != 0xfffffffa {
+		off = 4
+	}
+	off += (n - 1) * 4
+	if off+4 > len(fn) {
+		return 0
+	}
+	return readu32Func(t.endianess)(fn, off)
+}
+
+// FuncForPC returns the function containing pc
+func (t *GoPCLNTab) FuncForPC(pc uint64) (*GoFunc, error) {
+	i := sort.Search(len(t.Funcs), func(i int) bool {
+		return t.Funcs[i].Entry > pc
+	})
+	if i == 0 || pc >= t.Funcs[i-1].End {
+		return nil, fmt.Errorf("elf: no Go function at 0x%X: %w", pc, ErrNoGoFunc)
+	}
+	return &t.Funcs[i-1], nil
+}
+
+// LineForPC returns the source position of pc
+func (t *GoPCLNTab) LineForPC(pc uint64) (GoLine, error) {
+	fn, err := t.FuncForPC(pc)
+	if err != nil {
+		return GoLine{}, err
+	}
+	line := t.pcvalue(t.field(fn.data, 6), fn.Entry, pc)
+	file := t.pcvalue(t.field(fn.data, 5), fn.Entry, pc)
+	return GoLine{PC: pc, File: t.fileName(fn, file), Line: int(line)}, nil
+}
+
+// Lines returns the line table of a function, one entry per run of instructions
+func (t *GoPCLNTab) Lines(fn *GoFunc) []GoLine {
+	lines := make([]GoLine, 0)
+	off := t.field(fn.data, 6)
+	if off == 0 || int(off) >= len(t.pctab) {
+		return lines
+	}
+	b := &dwarfBuf{data: t.pctab[off:], endianess: t.endianess}
+	pc, val := fn.Entry, int32(-1)
+	for first := true; pc < fn.End; first = false {
+		start := pc
+		if !t.step(b, &pc, &val, first) {
+			break
+		}
+		file := t.pcvalue(t.field(fn.data, 5), fn.Entry, start)
+		lines = append(lines, GoLine{PC: start, File: t.fileName(fn, file), Line: int(val)})
+	}
+	return lines
+}
+
+// Files returns the names in the file table
+func (t *GoPCLNTab) Files() []string {
+	files := make([]string, 0)
+	if t.magic == 0xfffffffb {
+		// The file table offset follows the function table, it holds the
+		// count followed by offsets of the names
+		nfunctab := (&dwarfBuf{data: t.data, endianess: t.endianess, off: 8}).addr(t.PtrSize)
+		b := &dwarfBuf{data: t.data, endianess: t.endianess, off: 8 + int(t.PtrSize) + int(nfunctab*2+1)*int(t.PtrSize)}
+		fileOff := b.u32()
+		b.off = int(fileOff)
+		n := b.u32()
+		for i := uint32(1); i < n && b.err == nil; i++ {
+			name, _ := readCStr(t.data, int(b.u32()))
+			files = append(files, name)
+		}
+		return files
+	}
+	for off, i := 0, uint32(0); i < t.nfiletab && off < len(t.filetab); i++ {
+		name, ok := readCStr(t.filetab, off)
+		if !ok {
+			break
+		}
+		files = append(files, name)
+		off += len(name) + 1
+	}
+	return files
+}
+
+func (t *GoPCLNTab) fileName(fn *GoFunc, fno int32) string {
+	if fno < 0 {
+		return ""
+	}
+	u32 := readu32Func(t.endianess)
+	if t.magic == 0xfffffffb {
+		nfunctab := (&dwarfBuf{data: t.data, endianess: t.endianess, off: 8}).addr(t.PtrSize)
+		b := &dwarfBuf{data: t.data, endianess: t.endianess, off: 8 + int(t.PtrSize) + int(nfunctab*2+1)*int(t.PtrSize)}
+		fileOff := int(b.u32())
+		if b.err != nil || fileOff+4*(int(fno)+1) > len(t.data) {
+			return ""
+		}
+		name, _ := readCStr(t.data, int(u32(t.data, fileOff+4*int(fno))))
+		return name
+	}
+	// File numbers are relative to the compilation unit of the function
+	cuOff := t.field(fn.data, 8)
+	idx := 4 * (int(cuOff) + int(fno))
+	if idx+4 > len(t.cutab) {
+		return ""
+	}
+	nameOff := u32(t.cutab, idx)
+	if nameOff == ^uint32(0) {
+		return ""
+	}
+	name, _ := readCStr(t.filetab, int(nameOff))
+	return name
+}
+
+// pcvalue runs the pc-value table at off from the function entry and returns
+// the value at target
+func (t *GoPCLNTab) pcvalue(off uint32, entry uint64, target uint64) int32 {
+	if off == 0 || int(off) >= len(t.pctab) {
+		return -1
+	}
+	b := &dwarfBuf{data: t.pctab[off:], endianess: t.endianess}
+	pc, val := entry, int32(-1)
+	for first := true; t.step(b, &pc, &val, first); first = false {
+		if target < pc {
+			return val
+		}
+	}
+	return -1
+}
+
+// step decodes one (value delta, pc delta) pair of a pc-value table. The
+// value delta is zig-zag encoded, a zero value delta after the first pair ends the table
+func (t *GoPCLNTab) step(b *dwarfBuf, pc *uint64, val *int32, first bool) bool {
+	uvdelta := uint32(b.uleb())
+	if b.err != nil || (uvdelta == 0 && !first) {
+		return false
+	}
+	if uvdelta&1 != 0 {
+		uvdelta = ^(uvdelta >> 1)
+	} else {
+		uvdelta >>= 1
+	}
+	pcdelta := b.uleb() * uint64(t.Quantum)
+	if b.err != nil {
+		return false
+	}
+	*pc += pcdelta
+	*val += int32(uvdelta)
+	return true
+}
@@ -0,0 +1,113 @@
+package elf
+
+import "fmt"
+
+// GRPFlagsDecode map of section group flag bits to friendly string
+var GRPFlagsDecode = map[uint64]string{
+	0x1: "GRP_COMDAT", /* Only one copy of the group is kept by the linker */
+}
+
+// GRPFlagsEncode map of friendly string to section group flag bits
+var GRPFlagsEncode = map[string]uint64{
+	"GRP_COMDAT": 0x1,
+}
+
+// GRPMaskDecode map of the multi bit section group flag fields to friendly string
+var GRPMaskDecode = map[uint64]string{
+	0x0ff00000: "GRP_MASKOS",   /* OS-specific */
+	0xf0000000: "GRP_MASKPROC", /* Processor-specific */
+}
+
+// GRPMaskEncode map of friendly string to multi bit section group flag field
+var GRPMaskEncode = map[string]uint64{
+	"GRP_MASKOS":   0x0ff00000,
+	"GRP_MASKPROC": 0xf0000000,
+}
+
+// Group a SHT_GROUP section, the sections that are kept or discarded together
+type Group struct {
+	Index       int      /* Index of the SHT_GROUP section */
+	Section     string   /* Name of the SHT_GROUP section */
+	GRPFlags    uint32   /* First word of the section, see GRPFlagsDecode */
+	Flags       []string /* Friendly names of GRPFlags, OS and processor fields as GRP_MASKOS(0x...) */
+	Comdat      bool     /* GRP_COMDAT is set */
+	Signature   string   /* Name of the signature symbol, which identifies the group */
+	Members     []uint32 /* Indexes of the member sections */
+	MemberNames []string /* Names of the member sections */
+}
+
+// Groups decodes the SHT_GROUP sections
+func (e ELF64) Groups() ([]Group, error) {
+	return readGroups(e.SectionHeaders, e.Sections, e.Class(), e.FileHeader.EIDATA)
+}
+
+// Groups decodes the SHT_GROUP sections
+func (e ELF32) Groups() ([]Group, error) {
+	return readGroups(e.SectionTable(), e.Sections, e.Class(), e.FileHeader.EIDATA)
+}
+
+func readGroups(sHead []SectionHeader64, sections []Section, class uint8, endianess uint8) ([]Group, error) {
+	u32 := readu32Func(endianess)
+	symtabs := make(map[uint32][]Symbol)
+	groups := make([]Group, 0)
+	for i, h := range sHead {
+		if h.SHType != SHTypeEncode["SHT_GROUP"] {
+			continue
+		}
+		data := sections[i].Data
+		if len(data) < 4 || len(data)%4 != 0 {
+			return nil, formatErr(int64(h.SHOffset), "section group", nil, "size 0x%X not a whole number of words", len(data))
+		}
+
+		g := Group{Index: i, Section: h.SectionName}
+		g.GRPFlags = u32(data, 0)
+		g.Flags = groupFlagNames(uint64(g.GRPFlags))
+		g.Comdat = uint64(g.GRPFlags)&GRPFlagsEncode["GRP_COMDAT"] != 0
+
+		// sh_link is the symbol table and sh_info the signature symbol
+		symbols, ok := symtabs[h.SHLink]
+		if !ok {
+			if int(h.SHLink) >= len(sHead) {
+				return nil, formatErr(int64(h.SHOffset), "section group", nil, "sh_link 0x%X out of range", h.SHLink)
+			}
+			var err error
+			if symbols, err = readSymbolTable(sHead, sections, int(h.SHLink), class, endianess); err != nil {
+				return nil, err
+			}
+			symtabs[h.SHLink] = symbols
+		}
+		if int(h.SHInfo) >= len(symbols) {
+			return nil, formatErr(int64(h.SHOffset), "section group", nil, "signature symbol 0x%X out of range", h.SHInfo)
+		}
+		sym := symbols[h.SHInfo]
+		g.Signature = sym.Name
+		// Section symbols have no name of their own, the group takes the section's
+		if sym.Type == "STT_SECTION" && g.Signature == "" {
+			g.Signature = sym.Section
+		}
+
+		for off := 4; off < len(data); off += 4 {
+			member := u32(data, off)
+			if int(member) >= len(sHead) {
+				return nil, formatErr(int64(h.SHOffset)+int64(off), "section group", nil, "member section 0x%X out of range", member)
+			}
+			g.Members = append(g.Members, member)
+			g.MemberNames = append(g.MemberNames, sHead[member].SectionName)
+		}
+		groups = append(groups, g)
+	}
+	return groups, nil
+}
+
+// groupFlagNames decodes the single bit group flags, then names the OS and
+// processor specific fields with the bits set in them
+func groupFlagNames(flags uint64) []string {
+	masks := GRPMaskEncode["GRP_MASKOS"] | GRPMaskEncode["GRP_MASKPROC"]
+	names := decodeBits(flags&^masks, GRPFlagsDecode)
+	for _, mask := range []uint64{GRPMaskEncode["GRP_MASKOS"], GRPMaskEncode["GRP_MASKPROC"]} {
+		if field := flags & mask; field != 0 {
+			names = append(names, fmt.Sprintf("%s(0x%X)", GRPMaskDecode[mask], field))
+		}
+	}
+	return names
+}
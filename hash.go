@@ -0,0 +1,476 @@
+package elf
+
+import "fmt"
+
+// SysVHash decoded SHT_HASH / DT_HASH table
+type SysVHash struct {
+	NBucket uint32   /* Number of buckets */
+	NChain  uint32   /* Number of chain entries, equal to the number of dynamic symbols */
+	Buckets []uint32 /* Symbol index of the first symbol of each bucket */
+	Chains  []uint32 /* Symbol index of the next symbol with the same bucket */
+}
+
+// GNUHash decoded SHT_GNU_HASH / DT_GNU_HASH table
+type GNUHash struct {
+	NBuckets   uint32   /* Number of buckets */
+	SymOffset  uint32   /* Index of the first symbol covered by the table */
+	BloomSize  uint32   /* Number of bloom filter words */
+	BloomShift uint32   /* Shift applied to the hash for the second bloom bit */
+	Bloom      []uint64 /* Bloom filter, words are 32 or 64 bit depending on class */
+	Buckets    []uint32 /* Lowest symbol index of each bucket */
+	Chains     []uint32 /* Hash of each symbol from SymOffset, low bit marks the end of a chain */
+}
+
+// HashMismatch a disagreement between a hash table and the dynamic symbols
+type HashMismatch struct {
+	Table   string /* SHT_HASH or SHT_GNU_HASH */
+	Index   uint32 /* Index of the dynamic symbol */
+	Symbol  string /* Name of the dynamic symbol */
+	Problem string /* Description of the disagreement */
+}
+
+// SysVHashFunc is the hash function of SHT_HASH tables
+func SysVHashFunc(name string) uint32 {
+	var h uint32
+	for i := 0; i < len(name); i++ {
+		h = (h << 4) + uint32(name[i])
+		g := h & 0xf0000000
+		if g != 0 {
+			h ^= g >> 24
+		}
+		h &^= g
+	}
+	return h
+}
+
+// GNUHashFunc is the hash function of SHT_GNU_HASH tables
+func GNUHashFunc(name string) uint32 {
+	h := uint32(5381)
+	for i := 0; i < len(name); i++ {
+		h = h*33 + uint32(name[i])
+	}
+	return h
+}
+
+// dynamicSymbols reads the dynamic symbol table through the DT_ entries and
+// the loaded segments, so it works on files without section headers
+type dynamicSymbols struct {
+	table     DynamicTable
+	space     *AddressSpace
+	strtab    []byte
+	symtab    uint64
+	syment    uint64
+	class     uint8
+	endianess uint8
+	sections  []Symbol /* Dynamic symbols decoded from sections, with versions, if present */
+}
+
+func newDynamicSymbols(f File) (*dynamicSymbols, error) {
+	table, err := readDynamic(f)
+	if err != nil {
+		return nil, err
+	}
+	d := &dynamicSymbols{
+		table:     table,
+		space:     newAddressSpace(f.Segments(), f.Class(), f.Header().EIDATA),
+		class:     f.Class(),
+		endianess: f.Header().EIDATA,
+	}
+	var ok bool
+	if d.symtab, ok = table.value("DT_SYMTAB"); !ok {
+		return nil, ErrNoSymbols
+	}
+	d.syment, _ = table.value("DT_SYMENT")
+	if d.syment == 0 {
+		d.syment = symbol64Size
+		if d.class == CLASS32BIT {
+			d.syment = symbol32Size
+		}
+	}
+	if d.strtab, err = dynamicStrtab(f, table, 0); err != nil {
+		return nil, err
+	}
+	if syms, err := f.DynamicSymbols(); err == nil {
+		d.sections = syms
+	}
+	return d, nil
+}
+
+func (d *dynamicSymbols) symbol(index uint32) (Symbol, error) {
+	if int(index) < len(d.sections) {
+		return d.sections[index], nil
+	}
+	var s Symbol
+	buf := make([]byte, d.syment)
+	if _, err := d.space.ReadAt(buf, int64(d.symtab+uint64(index)*d.syment)); err != nil {
+		return s, err
+	}
+	if d.class == CLASS32BIT {
+		s.FromBuffer32(buf, d.endianess)
+	} else {
+		s.FromBuffer64(buf, d.endianess)
+	}
+	s.Name, _ = readCStr(d.strtab, int(s.STName))
+	s.Section = SHNDecode[s.STShndx]
+	if addr, ok := d.table.value("DT_VERSYM"); ok {
+		ver := make([]byte, 2)
+		if _, err := d.space.ReadAt(ver, int64(addr+2*uint64(index))); err == nil {
+			val := readu16Func(d.endianess)(ver, 0)
+			s.VersionIndex = val &^ versymHidden
+			s.VersionHidden = val&versymHidden != 0
+		}
+	}
+	return s, nil
+}
+
+func (d *dynamicSymbols) words(addr uint64, count uint64) ([]uint32, error) {
+	if err := d.space.checkMapped(addr, 4*count); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, 4*count)
+	if _, err := d.space.ReadAt(buf, int64(addr)); err != nil {
+		return nil, err
+	}
+	u32 := readu32Func(d.endianess)
+	words := make([]uint32, count)
+	for i := range words {
+		words[i] = u32(buf, 4*i)
+	}
+	return words, nil
+}
+
+func (d *dynamicSymbols) sysvHash() (*SysVHash, error) {
+	addr, ok := d.table.value("DT_HASH")
+	if !ok {
+		return nil, ErrNoHashTable
+	}
+	header, err := d.words(addr, 2)
+	if err != nil {
+		return nil, err
+	}
+	h := &SysVHash{NBucket: header[0], NChain: header[1]}
+	if h.Buckets, err = d.words(addr+8, uint64(h.NBucket)); err != nil {
+		return nil, err
+	}
+	if h.Chains, err = d.words(addr+8+4*uint64(h.NBucket), uint64(h.NChain)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+func (d *dynamicSymbols) gnuHash() (*GNUHash, error) {
+	addr, ok := d.table.value("DT_GNU_HASH")
+	if !ok {
+		return nil, ErrNoHashTable
+	}
+	header, err := d.words(addr, 4)
+	if err != nil {
+		return nil, err
+	}
+	h := &GNUHash{NBuckets: header[0], SymOffset: header[1], BloomSize: header[2], BloomShift: header[3]}
+
+	wordSize := uint64(8)
+	if d.class == CLASS32BIT {
+		wordSize = 4
+	}
+	bloomAddr := addr + 16
+	if err := d.space.checkMapped(bloomAddr, wordSize*uint64(h.BloomSize)); err != nil {
+		return nil, err
+	}
+	bloom := make([]byte, wordSize*uint64(h.BloomSize))
+	if _, err := d.space.ReadAt(bloom, int64(bloomAddr)); err != nil {
+		return nil, err
+	}
+	h.Bloom = make([]uint64, h.BloomSize)
+	for i := range h.Bloom {
+		if wordSize == 4 {
+			h.Bloom[i] = uint64(readu32Func(d.endianess)(bloom, 4*i))
+		} else {
+			h.Bloom[i] = readu64Func(d.endianess)(bloom, 8*i)
+		}
+	}
+
+	bucketAddr := bloomAddr + uint64(len(bloom))
+	if h.Buckets, err = d.words(bucketAddr, uint64(h.NBuckets)); err != nil {
+		return nil, err
+	}
+
+	// The table doesn't record its length, walk the chain of the highest
+	// bucket to its end to find the number of symbols
+	chainAddr := bucketAddr + 4*uint64(h.NBuckets)
+	last := uint32(0)
+	for _, b := range h.Buckets {
+		if b > last {
+			last = b
+		}
+	}
+	count := uint32(0)
+	if last >= h.SymOffset {
+		for i := last; ; i++ {
+			entry, err := d.words(chainAddr+4*uint64(i-h.SymOffset), 1)
+			if err != nil {
+				return nil, err
+			}
+			if entry[0]&1 != 0 {
+				count = i + 1 - h.SymOffset
+				break
+			}
+		}
+	}
+	if h.Chains, err = d.words(chainAddr, uint64(count)); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// lookup finds name through the hash table the way ld.so does, preferring
+// DT_GNU_HASH. Undefined and hidden versions of symbols are skipped
+func (d *dynamicSymbols) lookup(name string) (Symbol, error) {
+	if gh, err := d.gnuHash(); err == nil {
+		return d.lookupGNU(gh, name)
+	} else if err != ErrNoHashTable {
+		return Symbol{}, err
+	}
+	sh, err := d.sysvHash()
+	if err != nil {
+		return Symbol{}, err
+	}
+	return d.lookupSysV(sh, name)
+}
+
+func (d *dynamicSymbols) matches(index uint32, name string) (Symbol, bool, error) {
+	s, err := d.symbol(index)
+	if err != nil {
+		return s, false, err
+	}
+	if s.Name != name || s.STShndx == SHNEncode["SHN_UNDEF"] || s.VersionHidden {
+		return s, false, nil
+	}
+	return s, true, nil
+}
+
+func (d *dynamicSymbols) lookupSysV(h *SysVHash, name string) (Symbol, error) {
+	if h.NBucket == 0 {
+		return Symbol{}, ErrSymbolNotFound
+	}
+	seen := 0
+	for i := h.Buckets[SysVHashFunc(name)%h.NBucket]; i != 0 && int(i) < len(h.Chains); i = h.Chains[i] {
+		if s, ok, err := d.matches(i, name); err != nil || ok {
+			return s, err
+		}
+		if seen++; seen > len(h.Chains) {
+			return Symbol{}, formatErr(0, "SHT_HASH", nil, "chain loop at symbol 0x%X", i)
+		}
+	}
+	return Symbol{}, ErrSymbolNotFound
+}
+
+func (d *dynamicSymbols) lookupGNU(h *GNUHash, name string) (Symbol, error) {
+	if h.NBuckets == 0 || h.BloomSize == 0 {
+		return Symbol{}, ErrSymbolNotFound
+	}
+	hash := GNUHashFunc(name)
+	bits := uint32(64)
+	if d.class == CLASS32BIT {
+		bits = 32
+	}
+	word := h.Bloom[(hash/bits)%h.BloomSize]
+	mask := uint64(1)<<(hash%bits) | uint64(1)<<((hash>>h.BloomShift)%bits)
+	if word&mask != mask {
+		return Symbol{}, ErrSymbolNotFound
+	}
+
+	i := h.Buckets[hash%h.NBuckets]
+	if i < h.SymOffset {
+		return Symbol{}, ErrSymbolNotFound
+	}
+	for ; int(i-h.SymOffset) < len(h.Chains); i++ {
+		chain := h.Chains[i-h.SymOffset]
+		if hash|1 == chain|1 {
+			if s, ok, err := d.matches(i, name); err != nil || ok {
+				return s, err
+			}
+		}
+		if chain&1 != 0 {
+			break
+		}
+	}
+	return Symbol{}, ErrSymbolNotFound
+}
+
+// count returns the number of dynamic symbols, from the sections if present
+// or else from the hash tables
+func (d *dynamicSymbols) count() (uint32, error) {
+	if len(d.sections) != 0 {
+		return uint32(len(d.sections)), nil
+	}
+	if sh, err := d.sysvHash(); err == nil {
+		return sh.NChain, nil
+	}
+	gh, err := d.gnuHash()
+	if err != nil {
+		return 0, err
+	}
+	return gh.SymOffset + uint32(len(gh.Chains)), nil
+}
+
+func (d *dynamicSymbols) verify() ([]HashMismatch, error) {
+	count, err := d.count()
+	if err != nil {
+		return nil, err
+	}
+	if len(d.sections) == 0 {
+		if err := d.space.checkMapped(d.symtab, uint64(count)*d.syment); err != nil {
+			return nil, err
+		}
+	}
+	mismatches := make([]HashMismatch, 0)
+	symbols := make([]Symbol, count)
+	for i := range symbols {
+		if symbols[i], err = d.symbol(uint32(i)); err != nil {
+			return nil, err
+		}
+	}
+	report := func(table string, i uint32, format string, args ...interface{}) {
+		mismatches = append(mismatches, HashMismatch{
+			Table: table, Index: i, Symbol: symbols[i].Name, Problem: fmt.Sprintf(format, args...),
+		})
+	}
+
+	if sh, err := d.sysvHash(); err == nil {
+		if sh.NChain != count {
+			mismatches = append(mismatches, HashMismatch{
+				Table: "SHT_HASH", Problem: fmt.Sprintf("nchain 0x%X but 0x%X dynamic symbols", sh.NChain, count),
+			})
+		}
+		for i := uint32(1); i < count; i++ {
+			if sh.NBucket == 0 {
+				report("SHT_HASH", i, "no buckets")
+				break
+			}
+			found := false
+			seen := 0
+			for j := sh.Buckets[SysVHashFunc(symbols[i].Name)%sh.NBucket]; j != 0 && int(j) < len(sh.Chains) && seen <= len(sh.Chains); j = sh.Chains[j] {
+				if j == i {
+					found = true
+					break
+				}
+				seen++
+			}
+			if !found {
+				report("SHT_HASH", i, "not reachable from bucket 0x%X", SysVHashFunc(symbols[i].Name)%sh.NBucket)
+			}
+		}
+	} else if err != ErrNoHashTable {
+		return nil, err
+	}
+
+	if gh, err := d.gnuHash(); err == nil {
+		if gh.SymOffset+uint32(len(gh.Chains)) != count {
+			mismatches = append(mismatches, HashMismatch{
+				Table:   "SHT_GNU_HASH",
+				Problem: fmt.Sprintf("covers 0x%X symbols but 0x%X dynamic symbols", gh.SymOffset+uint32(len(gh.Chains)), count),
+			})
+		}
+		bits := uint32(64)
+		if d.class == CLASS32BIT {
+			bits = 32
+		}
+		for i := gh.SymOffset; i < count && int(i-gh.SymOffset) < len(gh.Chains); i++ {
+			hash := GNUHashFunc(symbols[i].Name)
+			if gh.Chains[i-gh.SymOffset]|1 != hash|1 {
+				report("SHT_GNU_HASH", i, "chain hash 0x%X, name hashes to 0x%X", gh.Chains[i-gh.SymOffset], hash)
+				continue
+			}
+			if gh.NBuckets == 0 || gh.BloomSize == 0 {
+				report("SHT_GNU_HASH", i, "no buckets")
+				break
+			}
+			word := gh.Bloom[(hash/bits)%gh.BloomSize]
+			mask := uint64(1)<<(hash%bits) | uint64(1)<<((hash>>gh.BloomShift)%bits)
+			if word&mask != mask {
+				report("SHT_GNU_HASH", i, "missing from bloom filter")
+			}
+			if start := gh.Buckets[hash%gh.NBuckets]; start < gh.SymOffset || start > i {
+				report("SHT_GNU_HASH", i, "bucket 0x%X starts at symbol 0x%X", hash%gh.NBuckets, start)
+			}
+		}
+	} else if err != ErrNoHashTable {
+		return nil, err
+	}
+	return mismatches, nil
+}
+
+// SysVHash decodes the DT_HASH table
+func (e ELF64) SysVHash() (*SysVHash, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.sysvHash()
+}
+
+// GNUHash decodes the DT_GNU_HASH table
+func (e ELF64) GNUHash() (*GNUHash, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.gnuHash()
+}
+
+// LookupDynamicSymbol finds a defined dynamic symbol through the hash tables,
+// like the dynamic linker. Works on files without section headers
+func (e ELF64) LookupDynamicSymbol(name string) (Symbol, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return Symbol{}, err
+	}
+	return d.lookup(name)
+}
+
+// VerifyHashTables reports where the hash tables disagree with the dynamic symbols
+func (e ELF64) VerifyHashTables() ([]HashMismatch, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.verify()
+}
+
+// SysVHash decodes the DT_HASH table
+func (e ELF32) SysVHash() (*SysVHash, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.sysvHash()
+}
+
+// GNUHash decodes the DT_GNU_HASH table
+func (e ELF32) GNUHash() (*GNUHash, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.gnuHash()
+}
+
+// LookupDynamicSymbol finds a defined dynamic symbol through the hash tables,
+// like the dynamic linker. Works on files without section headers
+func (e ELF32) LookupDynamicSymbol(name string) (Symbol, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return Symbol{}, err
+	}
+	return d.lookup(name)
+}
+
+// VerifyHashTables reports where the hash tables disagree with the dynamic symbols
+func (e ELF32) VerifyHashTables() ([]HashMismatch, error) {
+	d, err := newDynamicSymbols(e)
+	if err != nil {
+		return nil, err
+	}
+	return d.verify()
+}
@@ -0,0 +1,344 @@
+package elf
+
+import (
+	"encoding/hex"
+	"fmt"
+)
+
+// NTGNUDecode map of note types with owner "GNU" to friendly string
+var NTGNUDecode = map[uint32]string{
+	1: "NT_GNU_ABI_TAG",         /* ABI information */
+	2: "NT_GNU_HWCAP",           /* Synthetic hwcap information */
+	3: "NT_GNU_BUILD_ID",        /* Build ID bits as generated by ld --build-id */
+	4: "NT_GNU_GOLD_VERSION",    /* Version note generated by GNU gold */
+	5: "NT_GNU_PROPERTY_TYPE_0", /* Program property */
+}
+
+// NTGNUEncode map of friendly string to note type with owner "GNU"
+var NTGNUEncode = map[string]uint32{
+	"NT_GNU_ABI_TAG":         1,
+	"NT_GNU_HWCAP":           2,
+	"NT_GNU_BUILD_ID":        3,
+	"NT_GNU_GOLD_VERSION":    4,
+	"NT_GNU_PROPERTY_TYPE_0": 5,
+}
+
+// NTCoreDecode map of note types with owner "CORE" or "LINUX" to friendly string
+var NTCoreDecode = map[uint32]string{
+	1:          "NT_PRSTATUS",   /* Contains copy of prstatus struct */
+	2:          "NT_FPREGSET",   /* Contains copy of fpregset struct */
+	3:          "NT_PRPSINFO",   /* Contains copy of prpsinfo struct */
+	4:          "NT_TASKSTRUCT", /* Contains copy of task structure */
+	6:          "NT_AUXV",       /* Contains copy of auxv array */
+	0x200:      "NT_386_TLS",    /* i386 TLS slots (struct user_desc) */
+	0x202:      "NT_X86_XSTATE", /* x86 extended state using xsave */
+	0x400:      "NT_ARM_VFP",    /* ARM VFP/NEON registers */
+	0x401:      "NT_ARM_TLS",    /* ARM TLS register */
+	0x46494c45: "NT_FILE",       /* Contains information about mapped files */
+	0x46e62b7f: "NT_PRXFPREG",   /* Contains copy of user_fxsr_struct */
+	0x53494749: "NT_SIGINFO",    /* Contains copy of siginfo_t */
+}
+
+// NTCoreEncode map of friendly string to note type with owner "CORE" or "LINUX"
+var NTCoreEncode = map[string]uint32{
+	"NT_PRSTATUS":   1,
+	"NT_FPREGSET":   2,
+	"NT_PRPSINFO":   3,
+	"NT_TASKSTRUCT": 4,
+	"NT_AUXV":       6,
+	"NT_386_TLS":    0x200,
+	"NT_X86_XSTATE": 0x202,
+	"NT_ARM_VFP":    0x400,
+	"NT_ARM_TLS":    0x401,
+	"NT_FILE":       0x46494c45,
+	"NT_PRXFPREG":   0x46e62b7f,
+	"NT_SIGINFO":    0x53494749,
+}
+
+// NTGoDecode map of note types with owner "Go" to friendly string
+var NTGoDecode = map[uint32]string{
+	4: "NT_GO_BUILD_ID", /* Go build ID */
+}
+
+// noteTypeDecode maps note owner to the table of note types for that owner
+var noteTypeDecode = map[string]map[uint32]string{
+	"GNU":   NTGNUDecode,
+	"CORE":  NTCoreDecode,
+	"LINUX": NTCoreDecode,
+	"Go":    NTGoDecode,
+}
+
+// GNUPropertyDecode map of GNU property types to friendly string
+var GNUPropertyDecode = map[uint32]string{
+	0x1:        "GNU_PROPERTY_STACK_SIZE",            /* Stack size */
+	0x2:        "GNU_PROPERTY_NO_COPY_ON_PROTECTED",  /* No copy relocation on protected data symbol */
+	0xc0000000: "GNU_PROPERTY_AARCH64_FEATURE_1_AND", /* AArch64 features, see GNUPropertyAArch64Decode */
+	0xc0000002: "GNU_PROPERTY_X86_FEATURE_1_AND",     /* x86 features, see GNUPropertyX86FeatureDecode */
+	0xc0008002: "GNU_PROPERTY_X86_ISA_1_NEEDED",      /* x86 ISA level needed to run */
+	0xc0010001: "GNU_PROPERTY_X86_FEATURE_2_USED",    /* x86 features used */
+	0xc0010002: "GNU_PROPERTY_X86_ISA_1_USED",        /* x86 ISA level used */
+}
+
+// GNUPropertyX86FeatureDecode map of GNU_PROPERTY_X86_FEATURE_1_AND bits to friendly string
+var GNUPropertyX86FeatureDecode = map[uint64]string{
+	0x1: "IBT",   /* Indirect branch tracking */
+	0x2: "SHSTK", /* Shadow stack */
+}
+
+// GNUPropertyX86ISADecode map of GNU_PROPERTY_X86_ISA_1 bits to friendly string
+var GNUPropertyX86ISADecode = map[uint64]string{
+	0x1: "x86-64-baseline",
+	0x2: "x86-64-v2",
+	0x4: "x86-64-v3",
+	0x8: "x86-64-v4",
+}
+
+// GNUPropertyAArch64Decode map of GNU_PROPERTY_AARCH64_FEATURE_1_AND bits to friendly string
+var GNUPropertyAArch64Decode = map[uint64]string{
+	0x1: "BTI", /* Branch target identification */
+	0x2: "PAC", /* Pointer authentication */
+}
+
+// gnuPropertyBits maps GNU property types holding bitmasks to their bit names
+var gnuPropertyBits = map[uint32]map[uint64]string{
+	0xc0000000: GNUPropertyAArch64Decode,
+	0xc0000002: GNUPropertyX86FeatureDecode,
+	0xc0008002: GNUPropertyX86ISADecode,
+	0xc0010002: GNUPropertyX86ISADecode,
+}
+
+// ABITagOSDecode map of the OS word of NT_GNU_ABI_TAG to friendly string
+var ABITagOSDecode = map[uint32]string{
+	0: "Linux",
+	1: "Hurd",
+	2: "Solaris",
+	3: "FreeBSD",
+}
+
+// Note entry of a SHT_NOTE section or PT_NOTE segment
+type Note struct {
+	NNamesz   uint32 /* Size of the owner name including the nul byte */
+	NDescsz   uint32 /* Size of the descriptor */
+	NType     uint32 /* Owner specific note type */
+	Name      string /* Owner of the note, e.g. "GNU" */
+	Desc      []byte /* Descriptor contents */
+	Type      string /* Friendly name of NType */
+	Section   string /* Name of the section holding the note, if any */
+	class     uint8
+	endianess uint8
+}
+
+// ABITag decoded NT_GNU_ABI_TAG descriptor
+type ABITag struct {
+	OSWord   uint32 /* Operating system, see ABITagOSDecode */
+	Major    uint32 /* Earliest compatible kernel version */
+	Minor    uint32
+	Subminor uint32
+	OS       string /* Friendly name of OSWord */
+}
+
+// GNUProperty entry of a NT_GNU_PROPERTY_TYPE_0 descriptor
+type GNUProperty struct {
+	PRType   uint32   /* Property type */
+	PRDatasz uint32   /* Size of the property data */
+	Data     []byte   /* Property data */
+	Type     string   /* Friendly name of PRType */
+	Flags    []string /* Friendly names of the bits set, for bitmask properties */
+}
+
+// readNotes decodes the notes packed in data, each field padded to align
+func readNotes(data []byte, align uint64, class uint8, endianess uint8) ([]Note, error) {
+	u32 := readu32Func(endianess)
+	// Notes are aligned to 4 bytes unless the container asks for 8
+	if align != 8 {
+		align = 4
+	}
+	notes := make([]Note, 0)
+	for off := uint64(0); off+12 <= uint64(len(data)); {
+		var n Note
+		n.NNamesz = u32(data, int(off))
+		n.NDescsz = u32(data, int(off+4))
+		n.NType = u32(data, int(off+8))
+		n.class = class
+		n.endianess = endianess
+
+		nameOff := off + 12
+		descOff := alignUp(nameOff+uint64(n.NNamesz), align)
+		next := alignUp(descOff+uint64(n.NDescsz), align)
+		if descOff+uint64(n.NDescsz) > uint64(len(data)) || nameOff+uint64(n.NNamesz) > uint64(len(data)) {
+			return nil, formatErr(int64(off), "note", nil, "namesz 0x%X descsz 0x%X past end of notes", n.NNamesz, n.NDescsz)
+		}
+		name := data[nameOff : nameOff+uint64(n.NNamesz)]
+		if len(name) > 0 && name[len(name)-1] == 0 {
+			name = name[:len(name)-1]
+		}
+		n.Name = string(name)
+		n.Desc = data[descOff : descOff+uint64(n.NDescsz)]
+		n.Type = NoteTypeName(n.Name, n.NType)
+		notes = append(notes, n)
+		off = next
+	}
+	return notes, nil
+}
+
+func alignUp(val, align uint64) uint64 {
+	return (val + align - 1) &^ (align - 1)
+}
+
+// NoteTypeName returns the friendly name of a note type for owner
+func NoteTypeName(owner string, ntype uint32) string {
+	if name, ok := noteTypeDecode[owner][ntype]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%X", ntype)
+}
+
+// BuildID returns the hex encoded descriptor of a NT_GNU_BUILD_ID note
+func (n Note) BuildID() (string, error) {
+	if n.Name != "GNU" || n.NType != NTGNUEncode["NT_GNU_BUILD_ID"] {
+		return "", fmt.Errorf("elf: note %s %s is not a build-id", n.Name, n.Type)
+	}
+	return hex.EncodeToString(n.Desc), nil
+}
+
+// ABITag decodes a NT_GNU_ABI_TAG note
+func (n Note) ABITag() (ABITag, error) {
+	var t ABITag
+	if n.Name != "GNU" || n.NType != NTGNUEncode["NT_GNU_ABI_TAG"] {
+		return t, fmt.Errorf("elf: note %s %s is not an ABI tag", n.Name, n.Type)
+	}
+	if len(n.Desc) < 16 {
+		return t, formatErr(0, "NT_GNU_ABI_TAG", nil, "descriptor is only 0x%X bytes", len(n.Desc))
+	}
+	u32 := readu32Func(n.endianess)
+	t.OSWord = u32(n.Desc, 0x00)
+	t.Major = u32(n.Desc, 0x04)
+	t.Minor = u32(n.Desc, 0x08)
+	t.Subminor = u32(n.Desc, 0x0C)
+	t.OS = ABITagOSDecode[t.OSWord]
+	return t, nil
+}
+
+// Properties decodes a NT_GNU_PROPERTY_TYPE_0 note
+func (n Note) Properties() ([]GNUProperty, error) {
+	if n.Name != "GNU" || n.NType != NTGNUEncode["NT_GNU_PROPERTY_TYPE_0"] {
+		return nil, fmt.Errorf("elf: note %s %s is not a property note", n.Name, n.Type)
+	}
+	u32 := readu32Func(n.endianess)
+	align := uint64(8)
+	if n.class == CLASS32BIT {
+		align = 4
+	}
+	props := make([]GNUProperty, 0)
+	for off := uint64(0); off+8 <= uint64(len(n.Desc)); {
+		var p GNUProperty
+		p.PRType = u32(n.Desc, int(off))
+		p.PRDatasz = u32(n.Desc, int(off+4))
+		if off+8+uint64(p.PRDatasz) > uint64(len(n.Desc)) {
+			return nil, formatErr(int64(off), "GNU property", nil, "pr_datasz 0x%X past end of note", p.PRDatasz)
+		}
+		p.Data = n.Desc[off+8 : off+8+uint64(p.PRDatasz)]
+		p.Type = GNUPropertyDecode[p.PRType]
+		if p.Type == "" {
+			p.Type = fmt.Sprintf("0x%X", p.PRType)
+		}
+		if bits, ok := gnuPropertyBits[p.PRType]; ok && len(p.Data) >= 4 {
+			p.Flags = decodeBits(uint64(u32(p.Data, 0)), bits)
+		}
+		props = append(props, p)
+		off += 8 + alignUp(uint64(p.PRDatasz), align)
+	}
+	return props, nil
+}
+
+// Notes decodes the notes held in SHT_NOTE sections
+func (e ELF64) Notes() ([]Note, error) {
+	return sectionNotes(e)
+}
+
+// SegmentNotes decodes the notes held in PT_NOTE segments
+func (e ELF64) SegmentNotes() ([]Note, error) {
+	return segmentNotes(e)
+}
+
+// BuildID returns the hex encoded GNU build-id of the file
+func (e ELF64) BuildID() (string, error) {
+	return buildID(e)
+}
+
+// Notes decodes the notes held in SHT_NOTE sections
+func (e ELF32) Notes() ([]Note, error) {
+	return sectionNotes(e)
+}
+
+// SegmentNotes decodes the notes held in PT_NOTE segments
+func (e ELF32) SegmentNotes() ([]Note, error) {
+	return segmentNotes(e)
+}
+
+// BuildID returns the hex encoded GNU build-id of the file
+func (e ELF32) BuildID() (string, error) {
+	return buildID(e)
+}
+
+func sectionNotes(f File) ([]Note, error) {
+	sections := f.SectionData()
+	notes := make([]Note, 0)
+	for i, h := range f.SectionTable() {
+		if h.SHType != SHTypeEncode["SHT_NOTE"] {
+			continue
+		}
+		found, err := readNotes(sections[i].Data, h.SHAddrAlign, f.Class(), f.Header().EIDATA)
+		if err != nil {
+			return nil, err
+		}
+		for j := range found {
+			found[j].Section = h.SectionName
+		}
+		notes = append(notes, found...)
+	}
+	return notes, nil
+}
+
+func segmentNotes(f File) ([]Note, error) {
+	notes := make([]Note, 0)
+	for _, ph := range f.Segments() {
+		if ph.PType != PTypeEncode["PT_NOTE"] {
+			continue
+		}
+		found, err := readNotes(ph.Data, ph.PAlign, f.Class(), f.Header().EIDATA)
+		if err != nil {
+			return nil, err
+		}
+		notes = append(notes, found...)
+	}
+	return notes, nil
+}
+
+func buildID(f File) (string, error) {
+	notes, err := sectionNotes(f)
+	if err != nil {
+		return "", err
+	}
+	if n, ok := findBuildID(notes); ok {
+		return n.BuildID()
+	}
+	// Stripped files may keep the PT_NOTE segment but not the note sections
+	if notes, err = segmentNotes(f); err != nil {
+		return "", err
+	}
+	if n, ok := findBuildID(notes); ok {
+		return n.BuildID()
+	}
+	return "", ErrNoBuildID
+}
+
+// findBuildID returns the NT_GNU_BUILD_ID note among notes
+func findBuildID(notes []Note) (Note, bool) {
+	for _, n := range notes {
+		if n.Name == "GNU" && n.NType == NTGNUEncode["NT_GNU_BUILD_ID"] {
+			return n, true
+		}
+	}
+	return Note{}, false
+}
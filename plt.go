@@ -0,0 +1,235 @@
+package elf
+
+import (
+	"fmt"
+	"sort"
+)
+
+// gotRelocTypes relocation types the dynamic linker uses to fill GOT slots
+var gotRelocTypes = map[string]bool{
+	"R_X86_64_GLOB_DAT":   true,
+	"R_X86_64_JUMP_SLOT":  true,
+	"R_X86_64_IRELATIVE":  true,
+	"R_386_GLOB_DAT":      true,
+	"R_386_JMP_SLOT":      true,
+	"R_386_IRELATIVE":     true,
+	"R_AARCH64_GLOB_DAT":  true,
+	"R_AARCH64_JUMP_SLOT": true,
+	"R_AARCH64_IRELATIVE": true,
+	"R_ARM_GLOB_DAT":      true,
+	"R_ARM_JUMP_SLOT":     true,
+	"R_ARM_IRELATIVE":     true,
+	"R_RISCV_JUMP_SLOT":   true,
+	"R_RISCV_IRELATIVE":   true,
+}
+
+// pltStubScanners find the stubs of a PLT section and the GOT slot each one
+// jumps through, keyed by EMachine
+var pltStubScanners = map[uint16]func(data []byte, addr uint64, entSize uint64) []PLTEntry{
+	0x3E: x86_64PLTStubs,
+	0xB7: aarch64PLTStubs,
+}
+
+// PLTEntry a PLT stub and the imported symbol it jumps to
+type PLTEntry struct {
+	Address uint64     /* Address of the stub */
+	Size    uint64     /* Size of the stub */
+	Section string     /* Section holding the stub: .plt, .plt.sec or .plt.got */
+	GOT     uint64     /* Address of the GOT slot the stub jumps through */
+	Name    string     /* Imported symbol, with @VERSION when versioned */
+	Reloc   Relocation /* Relocation filling the GOT slot */
+}
+
+// GOTEntry a GOT slot filled in by the dynamic linker
+type GOTEntry struct {
+	Address uint64     /* Address of the slot */
+	Name    string     /* Symbol the slot resolves to, with @VERSION when versioned */
+	Reloc   Relocation /* JUMP_SLOT, GLOB_DAT or IRELATIVE relocation filling the slot */
+}
+
+// PLT the PLT stubs and GOT slots of a dynamically linked file
+type PLT struct {
+	Entries []PLTEntry /* Stubs sorted by address, only found for x86-64 and AArch64 */
+	GOT     []GOTEntry /* Slots sorted by address */
+}
+
+// PLT maps the stubs of .plt, .plt.sec and .plt.got and the GOT slots to the
+// imported symbols named by their relocations
+func (e ELF64) PLT() (*PLT, error) {
+	return readPLT(e)
+}
+
+// PLT maps the stubs of .plt, .plt.sec and .plt.got and the GOT slots to the
+// imported symbols named by their relocations
+func (e ELF32) PLT() (*PLT, error) {
+	return readPLT(e)
+}
+
+// PLTName returns the imported symbol reached through the PLT stub or GOT slot at addr
+func (e ELF64) PLTName(addr uint64) (string, error) {
+	return pltName(e, addr)
+}
+
+// PLTName returns the imported symbol reached through the PLT stub or GOT slot at addr
+func (e ELF32) PLTName(addr uint64) (string, error) {
+	return pltName(e, addr)
+}
+
+func pltName(f File, addr uint64) (string, error) {
+	p, err := readPLT(f)
+	if err != nil {
+		return "", err
+	}
+	return p.Name(addr)
+}
+
+func readPLT(f File) (*PLT, error) {
+	relocs, err := readRelocations(f)
+	if err != nil {
+		return nil, err
+	}
+	p := &PLT{}
+	for _, r := range relocs {
+		if gotRelocTypes[r.Type] {
+			p.GOT = append(p.GOT, GOTEntry{Address: r.ROffset, Name: gotSymbolName(r), Reloc: r})
+		}
+	}
+	if len(p.GOT) == 0 {
+		return nil, ErrNoPLT
+	}
+	sort.SliceStable(p.GOT, func(i, j int) bool {
+		return p.GOT[i].Address < p.GOT[j].Address
+	})
+	slots := make(map[uint64]int, len(p.GOT))
+	for i, g := range p.GOT {
+		slots[g.Address] = i
+	}
+
+	scan, ok := pltStubScanners[f.Header().EMachine]
+	if !ok {
+		return p, nil
+	}
+	sHead := f.SectionTable()
+	sections := f.SectionData()
+	for i, h := range sHead {
+		if h.SectionName != ".plt" && h.SectionName != ".plt.sec" && h.SectionName != ".plt.got" {
+			continue
+		}
+		for _, stub := range scan(sections[i].Data, h.SHAddr, h.SHEntsize) {
+			// PLT0 jumps through the reserved GOT slots, which have no relocation
+			slot, ok := slots[stub.GOT]
+			if !ok {
+				continue
+			}
+			stub.Section = h.SectionName
+			stub.Name = p.GOT[slot].Name
+			stub.Reloc = p.GOT[slot].Reloc
+			p.Entries = append(p.Entries, stub)
+		}
+	}
+	sort.SliceStable(p.Entries, func(i, j int) bool {
+		return p.Entries[i].Address < p.Entries[j].Address
+	})
+	return p, nil
+}
+
+// gotSymbolName names the target of a GOT relocation, IRELATIVE slots have no
+// symbol and are named after the resolver address like objdump does
+func gotSymbolName(r Relocation) string {
+	if r.SymIndex == 0 {
+		return fmt.Sprintf("*ABS*+0x%x", uint64(r.RAddend))
+	}
+	if r.Symbol.Version != "" {
+		return r.Symbol.Name + "@" + r.Symbol.Version
+	}
+	return r.Symbol.Name
+}
+
+// EntryForAddr returns the PLT stub containing addr
+func (p *PLT) EntryForAddr(addr uint64) (PLTEntry, error) {
+	i := sort.Search(len(p.Entries), func(i int) bool {
+		return p.Entries[i].Address+p.Entries[i].Size > addr
+	})
+	if i < len(p.Entries) && p.Entries[i].Address <= addr {
+		return p.Entries[i], nil
+	}
+	return PLTEntry{}, fmt.Errorf("elf: address 0x%X: %w", addr, ErrNoPLTEntry)
+}
+
+// Name returns the imported symbol reached through the PLT stub containing
+// addr or the GOT slot at addr
+func (p *PLT) Name(addr uint64) (string, error) {
+	if e, err := p.EntryForAddr(addr); err == nil {
+		return e.Name, nil
+	}
+	i := sort.Search(len(p.GOT), func(i int) bool {
+		return p.GOT[i].Address >= addr
+	})
+	if i < len(p.GOT) && p.GOT[i].Address == addr {
+		return p.GOT[i].Name, nil
+	}
+	return "", fmt.Errorf("elf: address 0x%X: %w", addr, ErrNoPLTEntry)
+}
+
+// x86_64PLTStubs splits a PLT section into entries and finds the
+// jmp *disp32(%rip) of each, which may follow an endbr64 and a bnd prefix
+func x86_64PLTStubs(data []byte, addr uint64, entSize uint64) []PLTEntry {
+	if entSize == 0 {
+		entSize = 16
+	}
+	var stubs []PLTEntry
+	for start := uint64(0); start+entSize <= uint64(len(data)); start += entSize {
+		entry := data[start : start+entSize]
+		for _, at := range []uint64{0, 1, 4, 5} {
+			if at+6 > entSize || entry[at] != 0xFF || entry[at+1] != 0x25 {
+				continue
+			}
+			next := addr + start + at + 6
+			stubs = append(stubs, PLTEntry{
+				Address: addr + start,
+				Size:    entSize,
+				GOT:     next + uint64(int64(int32(readu32le(entry, int(at+2))))),
+			})
+			break
+		}
+	}
+	return stubs
+}
+
+// aarch64PLTStubs finds the adrp x16 / ldr x17, [x16, #off] pairs that load
+// the GOT slot of each stub, including an optional leading bti c. AArch64
+// instructions are little endian whatever the data endianness
+func aarch64PLTStubs(data []byte, addr uint64, entSize uint64) []PLTEntry {
+	const (
+		btiC    = 0xD503245F
+		adrpX16 = 0x90000010 /* adrp x16, with the immediate masked out */
+		ldrX17  = 0xF9400211 /* ldr x17, [x16, #imm], with the immediate masked out */
+	)
+	var stubs []PLTEntry
+	for off := 0; off+8 <= len(data); off += 4 {
+		adrp := readu32le(data, off)
+		ldr := readu32le(data, off+4)
+		if adrp&0x9F00001F != adrpX16 || ldr&0xFFC003FF != ldrX17 {
+			continue
+		}
+		imm := uint64(adrp>>29&0x3) | uint64(adrp>>5&0x7FFFF)<<2
+		page := int64(imm<<43) >> 31 /* Sign extend the 21 bit page count and scale by 4KiB */
+		pc := addr + uint64(off)
+		start := pc
+		if off >= 4 && readu32le(data, off-4) == btiC {
+			start -= 4
+		}
+		stubs = append(stubs, PLTEntry{
+			Address: start,
+			GOT:     pc&^0xFFF + uint64(page) + uint64(ldr>>10&0xFFF)*8,
+		})
+	}
+	for i := range stubs {
+		end := addr + uint64(len(data))
+		if i+1 < len(stubs) {
+			end = stubs[i+1].Address
+		}
+		stubs[i].Size = end - stubs[i].Address
+	}
+	return stubs
+}
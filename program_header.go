@@ -13,6 +13,10 @@ var PTypeDecode = map[uint32]string{
 	0x00000006: "PT_PHDR",
 	0x00000007: "PT_TLS",
 	0x60000000: "PT_LOOS",
+	0x6474e550: "PT_GNU_EH_FRAME",
+	0x6474e551: "PT_GNU_STACK",
+	0x6474e552: "PT_GNU_RELRO",
+	0x6474e553: "PT_GNU_PROPERTY",
 	0x6FFFFFFF: "PT_HIOS",
 	0x70000000: "PT_LOPROC",
 	0x7FFFFFFF: "PT_HIPROC",
@@ -20,18 +24,22 @@ var PTypeDecode = map[uint32]string{
 
 // PTypeEncode map of friendly string to PType
 var PTypeEncode = map[string]uint32{
-	"PT_NULL":    0x00000000,
-	"PT_LOAD":    0x00000001,
-	"PT_DYNAMIC": 0x00000002,
-	"PT_INTERP":  0x00000003,
-	"PT_NOTE":    0x00000004,
-	"PT_SHLIB":   0x00000005,
-	"PT_PHDR":    0x00000006,
-	"PT_TLS":     0x00000007,
-	"PT_LOOS":    0x60000000,
-	"PT_HIOS":    0x6FFFFFFF,
-	"PT_LOPROC":  0x70000000,
-	"PT_HIPROC":  0x7FFFFFFF,
+	"PT_NULL":         0x00000000,
+	"PT_LOAD":         0x00000001,
+	"PT_DYNAMIC":      0x00000002,
+	"PT_INTERP":       0x00000003,
+	"PT_NOTE":         0x00000004,
+	"PT_SHLIB":        0x00000005,
+	"PT_PHDR":         0x00000006,
+	"PT_TLS":          0x00000007,
+	"PT_LOOS":         0x60000000,
+	"PT_GNU_EH_FRAME": 0x6474e550,
+	"PT_GNU_STACK":    0x6474e551,
+	"PT_GNU_RELRO":    0x6474e552,
+	"PT_GNU_PROPERTY": 0x6474e553,
+	"PT_HIOS":         0x6FFFFFFF,
+	"PT_LOPROC":       0x70000000,
+	"PT_HIPROC":       0x7FFFFFFF,
 }
 
 var flagsDecode = map[uint32]string{
@@ -61,7 +69,7 @@ type ProgramHeader64 struct {
 	SegmentType string   /* Pretty name for the PType */
 	Flags       []string /* Flag friend names */
 	FileOffset  uint64   /* Offset of header in the file */
-	Data        []byte   /* Actual data */
+	Data        []byte   /* Contents of the segment in the file image, PFilesz bytes from POffset */
 }
 
 // FromBuffer given a sufficiently sized, filled, buffer initialize the attrs of the ProgramHeader64
@@ -76,28 +84,182 @@ func (h *ProgramHeader64) FromBuffer(buf []byte, endianess uint8) {
 	h.PPaddr = u64(buf, 0x18)
 	h.PFilesz = u64(buf, 0x20)
 	h.PMemsz = u64(buf, 0x28)
+	h.PAlign = u64(buf, 0x30)
 	h.SegmentType = PTypeDecode[h.PType]
 	h.readFlags()
-	h.Data = make([]byte, len(buf))
-	copy(h.Data, buf)
 }
 
 func (h *ProgramHeader64) readFlags() {
-	if h.PFlags == 0 {
-		h.Flags = make([]string, 1)
-		h.Flags[0] = flagsDecode[0]
-		return
+	h.Flags = decodePFlags(h.PFlags)
+}
+
+func decodePFlags(pflags uint32) []string {
+	if pflags == 0 {
+		flags := make([]string, 1)
+		flags[0] = flagsDecode[0]
+		return flags
 	}
-	h.Flags = make([]string, 0, 32)
+	flags := make([]string, 0, 32)
 	for i := 0; i < 32; i++ {
 		var bitmask uint32 = 1 << i
-		present := (h.PFlags & bitmask) != 0
+		present := (pflags & bitmask) != 0
 		if present {
 			flagStr := flagsDecode[bitmask]
 			if flagStr == "" {
 				flagStr = fmt.Sprintf("0x%X", bitmask)
 			}
-			h.Flags = append(h.Flags, flagStr)
+			flags = append(flags, flagStr)
+		}
+	}
+	return flags
+}
+
+// ProgramHeader32 32 bit program header struct
+type ProgramHeader32 struct {
+	PType       uint32   /* Identifies the type of the segment. */
+	POffset     uint32   /* Offset of the segment in the file image. */
+	PVaddr      uint32   /* Virtual address of the segment in memory. */
+	PPaddr      uint32   /* On systems where physical address is relevant, reserved for segment's physical address. */
+	PFilesz     uint32   /* Size in bytes of the segment in the file image. May be 0. */
+	PMemsz      uint32   /* Size in bytes of the segment in memory. May be 0. */
+	PFlags      uint32   /* Segment-dependent flags (position for 32-bit structure). */
+	PAlign      uint32   /* 0 and 1 specify no alignment. Otherwise should be power of 2 */
+	SegmentType string   /* Pretty name for the PType */
+	Flags       []string /* Flag friend names */
+	FileOffset  uint64   /* Offset of header in the file */
+	Data        []byte   /* Contents of the segment in the file image, PFilesz bytes from POffset */
+}
+
+// FromBuffer given a sufficiently sized, filled, buffer initialize the attrs of the ProgramHeader32
+func (h *ProgramHeader32) FromBuffer(buf []byte, endianess uint8) {
+	u32 := readu32Func(endianess)
+
+	h.PType = u32(buf, 0x00)
+	h.POffset = u32(buf, 0x04)
+	h.PVaddr = u32(buf, 0x08)
+	h.PPaddr = u32(buf, 0x0C)
+	h.PFilesz = u32(buf, 0x10)
+	h.PMemsz = u32(buf, 0x14)
+	h.PFlags = u32(buf, 0x18)
+	h.PAlign = u32(buf, 0x1C)
+	h.SegmentType = PTypeDecode[h.PType]
+	h.readFlags()
+}
+
+func (h *ProgramHeader32) readFlags() {
+	h.Flags = decodePFlags(h.PFlags)
+}
+
+func (h ProgramHeader32) widen() ProgramHeader64 {
+	return ProgramHeader64{
+		PType:       h.PType,
+		PFlags:      h.PFlags,
+		POffset:     uint64(h.POffset),
+		PVaddr:      uint64(h.PVaddr),
+		PPaddr:      uint64(h.PPaddr),
+		PFilesz:     uint64(h.PFilesz),
+		PMemsz:      uint64(h.PMemsz),
+		PAlign:      uint64(h.PAlign),
+		SegmentType: h.SegmentType,
+		Flags:       h.Flags,
+		FileOffset:  h.FileOffset,
+		Data:        h.Data,
+	}
+}
+
+// SegmentSections returns, for each program header, the indexes of the
+// sections that fall inside the segment, like readelf's section to segment
+// mapping
+func (e ELF64) SegmentSections() [][]int {
+	return segmentSections(e.ProgramHeaders, e.SectionHeaders)
+}
+
+// SegmentSections returns, for each program header, the indexes of the
+// sections that fall inside the segment
+func (e ELF32) SegmentSections() [][]int {
+	return segmentSections(e.Segments(), e.SectionTable())
+}
+
+func segmentSections(pHead []ProgramHeader64, sHead []SectionHeader64) [][]int {
+	mapping := make([][]int, len(pHead))
+	for i, ph := range pHead {
+		mapping[i] = make([]int, 0)
+		for j, sh := range sHead {
+			if sectionInSegment(sh, ph) {
+				mapping[i] = append(mapping[i], j)
+			}
+		}
+	}
+	return mapping
+}
+
+// sectionInSegment follows the rules binutils uses for the section to segment
+// mapping: file offsets must fall in the segment's file image, and allocated
+// sections must also fall in its memory image
+func sectionInSegment(sh SectionHeader64, ph ProgramHeader64) bool {
+	if sh.SHType == SHTypeEncode["SHT_NULL"] {
+		return false
+	}
+	tls := sh.SHFlags&SHFlagsEncode["SHF_TLS"] != 0
+	alloc := sh.SHFlags&SHFlagsEncode["SHF_ALLOC"] != 0
+	nobits := sh.SHType == SHTypeEncode["SHT_NOBITS"]
+
+	// TLS sections only belong to PT_TLS, PT_LOAD and PT_GNU_RELRO, and only
+	// TLS sections belong to PT_TLS
+	switch ph.PType {
+	case PTypeEncode["PT_TLS"]:
+		if !tls {
+			return false
+		}
+	case PTypeEncode["PT_LOAD"], PTypeEncode["PT_GNU_RELRO"]:
+		// .tbss takes up no space in the loaded image
+		if tls && nobits {
+			return false
+		}
+	default:
+		if tls {
+			return false
+		}
+	}
+	if ph.PType == PTypeEncode["PT_PHDR"] && sh.SHSize != 0 {
+		return false
+	}
+	// Loaded segments only hold SHF_ALLOC sections
+	if !alloc {
+		switch ph.PType {
+		case PTypeEncode["PT_LOAD"], PTypeEncode["PT_DYNAMIC"], PTypeEncode["PT_GNU_EH_FRAME"],
+			PTypeEncode["PT_GNU_STACK"], PTypeEncode["PT_GNU_RELRO"]:
+			return false
+		}
+	}
+
+	if !nobits {
+		if sh.SHOffset < ph.POffset || sh.SHOffset-ph.POffset > ph.PFilesz {
+			return false
+		}
+		if sh.SHSize != 0 && sh.SHOffset+sh.SHSize > ph.POffset+ph.PFilesz {
+			return false
+		}
+	}
+	if alloc {
+		if sh.SHAddr < ph.PVaddr || sh.SHAddr-ph.PVaddr > ph.PMemsz {
+			return false
+		}
+		if sh.SHAddr+sh.SHSize > ph.PVaddr+ph.PMemsz {
+			return false
+		}
+	} else if nobits {
+		return false
+	}
+
+	// Empty sections are only in the segment when they are strictly inside it
+	if sh.SHSize == 0 {
+		if alloc && ph.PMemsz != 0 && sh.SHAddr == ph.PVaddr+ph.PMemsz {
+			return false
+		}
+		if !alloc && ph.PFilesz != 0 && sh.SHOffset == ph.POffset+ph.PFilesz {
+			return false
 		}
 	}
+	return true
 }
@@ -0,0 +1,130 @@
+package elf
+
+import "fmt"
+
+// SectionEntry a section header together with its contents
+type SectionEntry struct {
+	Index   int             /* Index in the section header table */
+	Header  SectionHeader64 /* Section header, widened for ELF32 */
+	Section Section         /* Section contents */
+}
+
+// SegmentEntry a program header, whose Data holds the segment contents
+type SegmentEntry struct {
+	Index  int             /* Index in the program header table */
+	Header ProgramHeader64 /* Program header, widened for ELF32 */
+}
+
+// SectionByName returns the first section called name
+func (e ELF64) SectionByName(name string) (SectionEntry, error) {
+	return sectionByName(e, name)
+}
+
+// SectionsByType returns the sections of type shType, see SHTypeEncode
+func (e ELF64) SectionsByType(shType uint32) []SectionEntry {
+	return sectionsByType(e, shType)
+}
+
+// SectionContaining returns the allocated section whose addresses contain addr
+func (e ELF64) SectionContaining(addr uint64) (SectionEntry, error) {
+	return sectionContaining(e, addr)
+}
+
+// SegmentContaining returns the PT_LOAD segment whose addresses contain addr
+func (e ELF64) SegmentContaining(addr uint64) (SegmentEntry, error) {
+	return segmentContaining(e, addr)
+}
+
+// SegmentsWithFlags returns the segments granting every permission in flags, a
+// mask of PF_X (0x1), PF_W (0x2) and PF_R (0x4)
+func (e ELF64) SegmentsWithFlags(flags uint32) []SegmentEntry {
+	return segmentsWithFlags(e, flags)
+}
+
+// SectionByName returns the first section called name
+func (e ELF32) SectionByName(name string) (SectionEntry, error) {
+	return sectionByName(e, name)
+}
+
+// SectionsByType returns the sections of type shType, see SHTypeEncode
+func (e ELF32) SectionsByType(shType uint32) []SectionEntry {
+	return sectionsByType(e, shType)
+}
+
+// SectionContaining returns the allocated section whose addresses contain addr
+func (e ELF32) SectionContaining(addr uint64) (SectionEntry, error) {
+	return sectionContaining(e, addr)
+}
+
+// SegmentContaining returns the PT_LOAD segment whose addresses contain addr
+func (e ELF32) SegmentContaining(addr uint64) (SegmentEntry, error) {
+	return segmentContaining(e, addr)
+}
+
+// SegmentsWithFlags returns the segments granting every permission in flags, a
+// mask of PF_X (0x1), PF_W (0x2) and PF_R (0x4)
+func (e ELF32) SegmentsWithFlags(flags uint32) []SegmentEntry {
+	return segmentsWithFlags(e, flags)
+}
+
+// sectionEntries pairs the section headers of f with their contents
+func sectionEntries(f File, keep func(h SectionHeader64) bool) []SectionEntry {
+	sHead := f.SectionTable()
+	sections := f.SectionData()
+	entries := make([]SectionEntry, 0)
+	for i, h := range sHead {
+		if i < len(sections) && keep(h) {
+			entries = append(entries, SectionEntry{Index: i, Header: h, Section: sections[i]})
+		}
+	}
+	return entries
+}
+
+func sectionByName(f File, name string) (SectionEntry, error) {
+	entries := sectionEntries(f, func(h SectionHeader64) bool {
+		return h.SectionName == name
+	})
+	if len(entries) == 0 {
+		return SectionEntry{}, fmt.Errorf("elf: section %q: %w", name, ErrNoSection)
+	}
+	return entries[0], nil
+}
+
+func sectionsByType(f File, shType uint32) []SectionEntry {
+	return sectionEntries(f, func(h SectionHeader64) bool {
+		return h.SHType == shType
+	})
+}
+
+func sectionContaining(f File, addr uint64) (SectionEntry, error) {
+	entries := sectionEntries(f, func(h SectionHeader64) bool {
+		if h.SHFlags&SHFlagsEncode["SHF_ALLOC"] == 0 || addr < h.SHAddr || addr-h.SHAddr >= h.SHSize {
+			return false
+		}
+		// .tbss takes no room in the address space, the sections after it overlap it
+		return h.SHFlags&SHFlagsEncode["SHF_TLS"] == 0 || h.SHType != SHTypeEncode["SHT_NOBITS"]
+	})
+	if len(entries) == 0 {
+		return SectionEntry{}, fmt.Errorf("elf: address 0x%X: %w", addr, ErrNoSection)
+	}
+	return entries[0], nil
+}
+
+func segmentContaining(f File, addr uint64) (SegmentEntry, error) {
+	for i, ph := range f.Segments() {
+		if ph.PType == PTypeEncode["PT_LOAD"] && addr >= ph.PVaddr && addr-ph.PVaddr < ph.PMemsz {
+			return SegmentEntry{Index: i, Header: ph}, nil
+		}
+	}
+	return SegmentEntry{}, fmt.Errorf("elf: address 0x%X: %w", addr, ErrUnmapped)
+}
+
+func segmentsWithFlags(f File, flags uint32) []SegmentEntry {
+	entries := make([]SegmentEntry, 0)
+	for i, ph := range f.Segments() {
+		if ph.PFlags&flags == flags {
+			entries = append(entries, SegmentEntry{Index: i, Header: ph})
+		}
+	}
+	return entries
+}
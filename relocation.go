@@ -0,0 +1,710 @@
+package elf
+
+import "fmt"
+
+// RX86_64Decode map of x86-64 relocation types to friendly string
+var RX86_64Decode = map[uint32]string{
+	0:  "R_X86_64_NONE",            /* No reloc */
+	1:  "R_X86_64_64",              /* Direct 64 bit */
+	2:  "R_X86_64_PC32",            /* PC relative 32 bit signed */
+	3:  "R_X86_64_GOT32",           /* 32 bit GOT entry */
+	4:  "R_X86_64_PLT32",           /* 32 bit PLT address */
+	5:  "R_X86_64_COPY",            /* Copy symbol at runtime */
+	6:  "R_X86_64_GLOB_DAT",        /* Create GOT entry */
+	7:  "R_X86_64_JUMP_SLOT",       /* Create PLT entry */
+	8:  "R_X86_64_RELATIVE",        /* Adjust by program base */
+	9:  "R_X86_64_GOTPCREL",        /* 32 bit signed PC relative offset to GOT */
+	10: "R_X86_64_32",              /* Direct 32 bit zero extended */
+	11: "R_X86_64_32S",             /* Direct 32 bit sign extended */
+	12: "R_X86_64_16",              /* Direct 16 bit zero extended */
+	13: "R_X86_64_PC16",            /* 16 bit sign extended pc relative */
+	14: "R_X86_64_8",               /* Direct 8 bit sign extended  */
+	15: "R_X86_64_PC8",             /* 8 bit sign extended pc relative */
+	16: "R_X86_64_DTPMOD64",        /* ID of module containing symbol */
+	17: "R_X86_64_DTPOFF64",        /* Offset in module's TLS block */
+	18: "R_X86_64_TPOFF64",         /* Offset in initial TLS block */
+	19: "R_X86_64_TLSGD",           /* 32 bit signed PC relative offset to two GOT entries for GD symbol */
+	20: "R_X86_64_TLSLD",           /* 32 bit signed PC relative offset to two GOT entries for LD symbol */
+	21: "R_X86_64_DTPOFF32",        /* Offset in TLS block */
+	22: "R_X86_64_GOTTPOFF",        /* 32 bit signed PC relative offset to GOT entry for IE symbol */
+	23: "R_X86_64_TPOFF32",         /* Offset in initial TLS block */
+	24: "R_X86_64_PC64",            /* PC relative 64 bit */
+	25: "R_X86_64_GOTOFF64",        /* 64 bit offset to GOT */
+	26: "R_X86_64_GOTPC32",         /* 32 bit signed pc relative offset to GOT */
+	27: "R_X86_64_GOT64",           /* 64-bit GOT entry offset */
+	28: "R_X86_64_GOTPCREL64",      /* 64-bit PC relative offset to GOT entry */
+	29: "R_X86_64_GOTPC64",         /* 64-bit PC relative offset to GOT */
+	30: "R_X86_64_GOTPLT64",        /* like GOT64, says PLT entry needed */
+	31: "R_X86_64_PLTOFF64",        /* 64-bit GOT relative offset to PLT entry */
+	32: "R_X86_64_SIZE32",          /* Size of symbol plus 32-bit addend */
+	33: "R_X86_64_SIZE64",          /* Size of symbol plus 64-bit addend */
+	34: "R_X86_64_GOTPC32_TLSDESC", /* GOT offset for TLS descriptor */
+	35: "R_X86_64_TLSDESC_CALL",    /* Marker for call through TLS descriptor */
+	36: "R_X86_64_TLSDESC",         /* TLS descriptor */
+	37: "R_X86_64_IRELATIVE",       /* Adjust indirectly by program base */
+	38: "R_X86_64_RELATIVE64",      /* 64-bit adjust by program base */
+	41: "R_X86_64_GOTPCRELX",       /* Load from 32 bit signed pc relative offset to GOT entry without REX prefix, relaxable */
+	42: "R_X86_64_REX_GOTPCRELX",   /* Load from 32 bit signed pc relative offset to GOT entry with REX prefix, relaxable */
+}
+
+// R386Decode map of i386 relocation types to friendly string
+var R386Decode = map[uint32]string{
+	0:  "R_386_NONE",          /* No reloc */
+	1:  "R_386_32",            /* Direct 32 bit  */
+	2:  "R_386_PC32",          /* PC relative 32 bit */
+	3:  "R_386_GOT32",         /* 32 bit GOT entry */
+	4:  "R_386_PLT32",         /* 32 bit PLT address */
+	5:  "R_386_COPY",          /* Copy symbol at runtime */
+	6:  "R_386_GLOB_DAT",      /* Create GOT entry */
+	7:  "R_386_JMP_SLOT",      /* Create PLT entry */
+	8:  "R_386_RELATIVE",      /* Adjust by program base */
+	9:  "R_386_GOTOFF",        /* 32 bit offset to GOT */
+	10: "R_386_GOTPC",         /* 32 bit PC relative offset to GOT */
+	11: "R_386_32PLT",         /* Direct 32 bit PLT address */
+	14: "R_386_TLS_TPOFF",     /* Offset in static TLS block */
+	15: "R_386_TLS_IE",        /* Address of GOT entry for static TLS block offset */
+	16: "R_386_TLS_GOTIE",     /* GOT entry for static TLS block offset */
+	17: "R_386_TLS_LE",        /* Offset relative to static TLS block */
+	18: "R_386_TLS_GD",        /* Direct 32 bit for GNU version of general dynamic thread local data */
+	19: "R_386_TLS_LDM",       /* Direct 32 bit for GNU version of local dynamic thread local data in LE code */
+	20: "R_386_16",            /* Direct 16 bit */
+	21: "R_386_PC16",          /* PC relative 16 bit */
+	22: "R_386_8",             /* Direct 8 bit */
+	23: "R_386_PC8",           /* PC relative 8 bit */
+	24: "R_386_TLS_GD_32",     /* Direct 32 bit for general dynamic thread local data */
+	25: "R_386_TLS_GD_PUSH",   /* Tag for pushl in GD TLS code */
+	26: "R_386_TLS_GD_CALL",   /* Relocation for call to __tls_get_addr() */
+	27: "R_386_TLS_GD_POP",    /* Tag for popl in GD TLS code */
+	28: "R_386_TLS_LDM_32",    /* Direct 32 bit for local dynamic thread local data in LE code */
+	29: "R_386_TLS_LDM_PUSH",  /* Tag for pushl in LDM TLS code */
+	30: "R_386_TLS_LDM_CALL",  /* Relocation for call to __tls_get_addr() in LDM code */
+	31: "R_386_TLS_LDM_POP",   /* Tag for popl in LDM TLS code */
+	32: "R_386_TLS_LDO_32",    /* Offset relative to TLS block */
+	33: "R_386_TLS_IE_32",     /* GOT entry for negated static TLS block offset */
+	34: "R_386_TLS_LE_32",     /* Negated offset relative to static TLS block */
+	35: "R_386_TLS_DTPMOD32",  /* ID of module containing symbol */
+	36: "R_386_TLS_DTPOFF32",  /* Offset in TLS block */
+	37: "R_386_TLS_TPOFF32",   /* Negated offset in static TLS block */
+	38: "R_386_SIZE32",        /* 32-bit symbol size */
+	39: "R_386_TLS_GOTDESC",   /* GOT offset for TLS descriptor */
+	40: "R_386_TLS_DESC_CALL", /* Marker of call through TLS descriptor for relaxation */
+	41: "R_386_TLS_DESC",      /* TLS descriptor containing pointer to code and to argument */
+	42: "R_386_IRELATIVE",     /* Adjust indirectly by program base */
+	43: "R_386_GOT32X",        /* Load from 32 bit GOT entry, relaxable */
+}
+
+// RAArch64Decode map of AArch64 relocation types to friendly string
+var RAArch64Decode = map[uint32]string{
+	0:    "R_AARCH64_NONE",                        /* No relocation */
+	257:  "R_AARCH64_ABS64",                       /* Direct 64 bit */
+	258:  "R_AARCH64_ABS32",                       /* Direct 32 bit */
+	259:  "R_AARCH64_ABS16",                       /* Direct 16-bit */
+	260:  "R_AARCH64_PREL64",                      /* PC-relative 64-bit */
+	261:  "R_AARCH64_PREL32",                      /* PC-relative 32-bit */
+	262:  "R_AARCH64_PREL16",                      /* PC-relative 16-bit */
+	263:  "R_AARCH64_MOVW_UABS_G0",                /* Dir. MOVZ imm. from bits 15:0 */
+	264:  "R_AARCH64_MOVW_UABS_G0_NC",             /* Likewise for MOVK; no check */
+	265:  "R_AARCH64_MOVW_UABS_G1",                /* Dir. MOVZ imm. from bits 31:16 */
+	266:  "R_AARCH64_MOVW_UABS_G1_NC",             /* Likewise for MOVK; no check */
+	267:  "R_AARCH64_MOVW_UABS_G2",                /* Dir. MOVZ imm. from bits 47:32 */
+	268:  "R_AARCH64_MOVW_UABS_G2_NC",             /* Likewise for MOVK; no check */
+	269:  "R_AARCH64_MOVW_UABS_G3",                /* Dir. MOV{K,Z} imm. from 63:48 */
+	270:  "R_AARCH64_MOVW_SABS_G0",                /* Dir. MOV{N,Z} imm. from 15:0 */
+	271:  "R_AARCH64_MOVW_SABS_G1",                /* Dir. MOV{N,Z} imm. from 31:16 */
+	272:  "R_AARCH64_MOVW_SABS_G2",                /* Dir. MOV{N,Z} imm. from 47:32 */
+	273:  "R_AARCH64_LD_PREL_LO19",                /* PC-rel. LD imm. from bits 20:2 */
+	274:  "R_AARCH64_ADR_PREL_LO21",               /* PC-rel. ADR imm. from bits 20:0 */
+	275:  "R_AARCH64_ADR_PREL_PG_HI21",            /* Page-rel. ADRP imm. from 32:12 */
+	276:  "R_AARCH64_ADR_PREL_PG_HI21_NC",         /* Likewise; no overflow check */
+	277:  "R_AARCH64_ADD_ABS_LO12_NC",             /* Dir. ADD imm. from bits 11:0 */
+	278:  "R_AARCH64_LDST8_ABS_LO12_NC",           /* Likewise for LD/ST; no check */
+	279:  "R_AARCH64_TSTBR14",                     /* PC-rel. TBZ/TBNZ imm. from 15:2 */
+	280:  "R_AARCH64_CONDBR19",                    /* PC-rel. cond. br. imm. from 20:2 */
+	282:  "R_AARCH64_JUMP26",                      /* PC-rel. B imm. from bits 27:2 */
+	283:  "R_AARCH64_CALL26",                      /* Likewise for CALL */
+	284:  "R_AARCH64_LDST16_ABS_LO12_NC",          /* Dir. ADD imm. from bits 11:1 */
+	285:  "R_AARCH64_LDST32_ABS_LO12_NC",          /* Likewise for bits 11:2 */
+	286:  "R_AARCH64_LDST64_ABS_LO12_NC",          /* Likewise for bits 11:3 */
+	287:  "R_AARCH64_MOVW_PREL_G0",                /* PC-rel. MOV{N,Z} imm. from 15:0 */
+	288:  "R_AARCH64_MOVW_PREL_G0_NC",             /* Likewise for MOVK; no check */
+	289:  "R_AARCH64_MOVW_PREL_G1",                /* PC-rel. MOV{N,Z} imm. from 31:16 */
+	290:  "R_AARCH64_MOVW_PREL_G1_NC",             /* Likewise for MOVK; no check */
+	291:  "R_AARCH64_MOVW_PREL_G2",                /* PC-rel. MOV{N,Z} imm. from 47:32 */
+	292:  "R_AARCH64_MOVW_PREL_G2_NC",             /* Likewise for MOVK; no check */
+	293:  "R_AARCH64_MOVW_PREL_G3",                /* PC-rel. MOV{N,Z} imm. from 63:48 */
+	299:  "R_AARCH64_LDST128_ABS_LO12_NC",         /* Dir. ADD imm. from bits 11:4 */
+	300:  "R_AARCH64_MOVW_GOTOFF_G0",              /* GOT-rel. off. MOV{N,Z} imm. 15:0 */
+	301:  "R_AARCH64_MOVW_GOTOFF_G0_NC",           /* Likewise for MOVK; no check */
+	302:  "R_AARCH64_MOVW_GOTOFF_G1",              /* GOT-rel. o. MOV{N,Z} imm. 31:16 */
+	303:  "R_AARCH64_MOVW_GOTOFF_G1_NC",           /* Likewise for MOVK; no check */
+	304:  "R_AARCH64_MOVW_GOTOFF_G2",              /* GOT-rel. o. MOV{N,Z} imm. 47:32 */
+	305:  "R_AARCH64_MOVW_GOTOFF_G2_NC",           /* Likewise for MOVK; no check */
+	306:  "R_AARCH64_MOVW_GOTOFF_G3",              /* GOT-rel. o. MOV{N,Z} imm. 63:48 */
+	307:  "R_AARCH64_GOTREL64",                    /* GOT-relative 64-bit */
+	308:  "R_AARCH64_GOTREL32",                    /* GOT-relative 32-bit */
+	309:  "R_AARCH64_GOT_LD_PREL19",               /* PC-rel. GOT off. load imm. 20:2 */
+	310:  "R_AARCH64_LD64_GOTOFF_LO15",            /* GOT-rel. off. LD/ST imm. 14:3 */
+	311:  "R_AARCH64_ADR_GOT_PAGE",                /* P-page-rel. GOT off. ADRP 32:12 */
+	312:  "R_AARCH64_LD64_GOT_LO12_NC",            /* Dir. GOT off. LD/ST imm. 11:3 */
+	313:  "R_AARCH64_LD64_GOTPAGE_LO15",           /* GOT-page-rel. GOT off. LD/ST 14:3 */
+	512:  "R_AARCH64_TLSGD_ADR_PREL21",            /* PC-relative ADR imm. 20:0 */
+	513:  "R_AARCH64_TLSGD_ADR_PAGE21",            /* page-rel. ADRP imm. 32:12 */
+	514:  "R_AARCH64_TLSGD_ADD_LO12_NC",           /* direct ADD imm. from 11:0 */
+	515:  "R_AARCH64_TLSGD_MOVW_G1",               /* GOT-rel. MOV{N,Z} 31:16 */
+	516:  "R_AARCH64_TLSGD_MOVW_G0_NC",            /* GOT-rel. MOVK imm. 15:0 */
+	517:  "R_AARCH64_TLSLD_ADR_PREL21",            /* Like 512; local dynamic model */
+	518:  "R_AARCH64_TLSLD_ADR_PAGE21",            /* Like 513; local dynamic model */
+	519:  "R_AARCH64_TLSLD_ADD_LO12_NC",           /* Like 514; local dynamic model */
+	539:  "R_AARCH64_TLSIE_MOVW_GOTTPREL_G1",      /* GOT-rel. MOV{N,Z} 31:16 */
+	540:  "R_AARCH64_TLSIE_MOVW_GOTTPREL_G0_NC",   /* GOT-rel. MOVK 15:0 */
+	541:  "R_AARCH64_TLSIE_ADR_GOTTPREL_PAGE21",   /* Page-rel. ADRP 32:12 */
+	542:  "R_AARCH64_TLSIE_LD64_GOTTPREL_LO12_NC", /* Direct LD off. 11:3 */
+	543:  "R_AARCH64_TLSIE_LD_GOTTPREL_PREL19",    /* PC-rel. load imm. 20:2 */
+	544:  "R_AARCH64_TLSLE_MOVW_TPREL_G2",         /* TLS TP-rel. MOV{N,Z} 47:32 */
+	545:  "R_AARCH64_TLSLE_MOVW_TPREL_G1",         /* TLS TP-rel. MOV{N,Z} 31:16 */
+	546:  "R_AARCH64_TLSLE_MOVW_TPREL_G1_NC",      /* Likewise; MOVK; no check */
+	547:  "R_AARCH64_TLSLE_MOVW_TPREL_G0",         /* TLS TP-rel. MOV{N,Z} 15:0 */
+	548:  "R_AARCH64_TLSLE_MOVW_TPREL_G0_NC",      /* Likewise; MOVK; no check */
+	549:  "R_AARCH64_TLSLE_ADD_TPREL_HI12",        /* TP-rel. ADD imm. 23:12 */
+	550:  "R_AARCH64_TLSLE_ADD_TPREL_LO12",        /* TP-rel. ADD imm. 11:0 */
+	551:  "R_AARCH64_TLSLE_ADD_TPREL_LO12_NC",     /* Likewise; no ovfl. check */
+	560:  "R_AARCH64_TLSDESC_LD_PREL19",           /* PC-rel. load immediate 20:2 */
+	561:  "R_AARCH64_TLSDESC_ADR_PREL21",          /* PC-rel. ADR immediate 20:0 */
+	562:  "R_AARCH64_TLSDESC_ADR_PAGE21",          /* Page-rel. ADRP imm. 32:12 */
+	563:  "R_AARCH64_TLSDESC_LD64_LO12",           /* Direct LD off. from 11:3 */
+	564:  "R_AARCH64_TLSDESC_ADD_LO12",            /* Direct ADD imm. from 11:0 */
+	565:  "R_AARCH64_TLSDESC_OFF_G1",              /* GOT-rel. MOV{N,Z} imm. 31:16 */
+	566:  "R_AARCH64_TLSDESC_OFF_G0_NC",           /* GOT-rel. MOVK imm. 15:0; no ck */
+	567:  "R_AARCH64_TLSDESC_LDR",                 /* Relax LDR */
+	568:  "R_AARCH64_TLSDESC_ADD",                 /* Relax ADD */
+	569:  "R_AARCH64_TLSDESC_CALL",                /* Relax BLR */
+	1024: "R_AARCH64_COPY",                        /* Copy symbol at runtime */
+	1025: "R_AARCH64_GLOB_DAT",                    /* Create GOT entry */
+	1026: "R_AARCH64_JUMP_SLOT",                   /* Create PLT entry */
+	1027: "R_AARCH64_RELATIVE",                    /* Adjust by program base */
+	1028: "R_AARCH64_TLS_DTPMOD",                  /* Module number, 64 bit */
+	1029: "R_AARCH64_TLS_DTPREL",                  /* Module-relative offset, 64 bit */
+	1030: "R_AARCH64_TLS_TPREL",                   /* TP-relative offset, 64 bit */
+	1031: "R_AARCH64_TLSDESC",                     /* TLS Descriptor */
+	1032: "R_AARCH64_IRELATIVE",                   /* STT_GNU_IFUNC relocation */
+}
+
+// RARMDecode map of ARM relocation types to friendly string
+var RARMDecode = map[uint32]string{
+	0:   "R_ARM_NONE",              /* No reloc */
+	1:   "R_ARM_PC24",              /* Deprecated PC relative 26 bit branch */
+	2:   "R_ARM_ABS32",             /* Direct 32 bit  */
+	3:   "R_ARM_REL32",             /* PC relative 32 bit */
+	4:   "R_ARM_PC13",              /* PC relative 13 bit */
+	5:   "R_ARM_ABS16",             /* Direct 16 bit */
+	6:   "R_ARM_ABS12",             /* Direct 12 bit */
+	7:   "R_ARM_THM_ABS5",          /* Direct & 0x7C (LDR, STR) */
+	8:   "R_ARM_ABS8",              /* Direct 8 bit */
+	9:   "R_ARM_SBREL32",           /* Static base relative 32 bit */
+	10:  "R_ARM_THM_PC22",          /* PC relative 24 bit (Thumb32 BL) */
+	11:  "R_ARM_THM_PC8",           /* PC relative & 0x3FC (Thumb16 LDR, ADD, ADR) */
+	12:  "R_ARM_AMP_VCALL9",        /* Obsolete */
+	13:  "R_ARM_TLS_DESC",          /* Dynamic relocation */
+	14:  "R_ARM_THM_SWI8",          /* Reserved */
+	15:  "R_ARM_XPC25",             /* Reserved */
+	16:  "R_ARM_THM_XPC22",         /* Reserved */
+	17:  "R_ARM_TLS_DTPMOD32",      /* ID of module containing symbol */
+	18:  "R_ARM_TLS_DTPOFF32",      /* Offset in TLS block */
+	19:  "R_ARM_TLS_TPOFF32",       /* Offset in static TLS block */
+	20:  "R_ARM_COPY",              /* Copy symbol at runtime */
+	21:  "R_ARM_GLOB_DAT",          /* Create GOT entry */
+	22:  "R_ARM_JUMP_SLOT",         /* Create PLT entry */
+	23:  "R_ARM_RELATIVE",          /* Adjust by program base */
+	24:  "R_ARM_GOTOFF",            /* 32 bit offset to GOT */
+	25:  "R_ARM_GOTPC",             /* 32 bit PC relative offset to GOT */
+	26:  "R_ARM_GOT32",             /* 32 bit GOT entry */
+	27:  "R_ARM_PLT32",             /* Deprecated, 32 bit PLT address */
+	28:  "R_ARM_CALL",              /* PC relative 24 bit (BL, BLX) */
+	29:  "R_ARM_JUMP24",            /* PC relative 24 bit (B, BL<cond>) */
+	30:  "R_ARM_THM_JUMP24",        /* PC relative 24 bit (Thumb32 B.W) */
+	31:  "R_ARM_BASE_ABS",          /* Adjust by program base */
+	38:  "R_ARM_TARGET1",           /* Platform specific, ABS32 or REL32 */
+	39:  "R_ARM_SBREL31",           /* Static base relative 31 bit */
+	40:  "R_ARM_V4BX",              /* Marker for BX instructions */
+	41:  "R_ARM_TARGET2",           /* Platform specific, REL32 or ABS32 or GOT_PREL */
+	42:  "R_ARM_PREL31",            /* PC relative 31 bit */
+	43:  "R_ARM_MOVW_ABS_NC",       /* Direct 16-bit (MOVW) */
+	44:  "R_ARM_MOVT_ABS",          /* Direct high 16-bit (MOVT) */
+	45:  "R_ARM_MOVW_PREL_NC",      /* PC relative 16-bit (MOVW) */
+	46:  "R_ARM_MOVT_PREL",         /* PC relative (MOVT) */
+	47:  "R_ARM_THM_MOVW_ABS_NC",   /* Direct 16 bit (Thumb32 MOVW) */
+	48:  "R_ARM_THM_MOVT_ABS",      /* Direct high 16 bit (Thumb32 MOVT) */
+	49:  "R_ARM_THM_MOVW_PREL_NC",  /* PC relative 16 bit (Thumb32 MOVW) */
+	50:  "R_ARM_THM_MOVT_PREL",     /* PC relative high 16 bit (Thumb32 MOVT) */
+	51:  "R_ARM_THM_JUMP19",        /* PC relative 20 bit (Thumb32 B<cond>.W) */
+	52:  "R_ARM_THM_JUMP6",         /* PC relative X & 0x7E (Thumb16 CBZ, CBNZ) */
+	53:  "R_ARM_THM_ALU_PREL_11_0", /* PC relative 12 bit (Thumb32 ADDW, SUBW) */
+	54:  "R_ARM_THM_PC12",          /* PC relative 12 bit (Thumb32 LDR{D,SB,H,SH}) */
+	55:  "R_ARM_ABS32_NOI",         /* Direct 32-bit */
+	56:  "R_ARM_REL32_NOI",         /* PC relative 32-bit */
+	94:  "R_ARM_PLT32_ABS",         /* Direct 32-bit PLT address */
+	95:  "R_ARM_GOT_ABS",           /* GOT entry */
+	96:  "R_ARM_GOT_PREL",          /* PC relative GOT entry */
+	97:  "R_ARM_GOT_BREL12",        /* GOT entry relative to GOT origin (LDR) */
+	98:  "R_ARM_GOTOFF12",          /* 12 bit, GOT entry relative to GOT origin (LDR, STR) */
+	99:  "R_ARM_GOTRELAX",          /* Reserved */
+	100: "R_ARM_GNU_VTENTRY",       /* Deprecated */
+	101: "R_ARM_GNU_VTINHERIT",     /* Deprecated */
+	102: "R_ARM_THM_PC11",          /* PC relative & 0xFFE (Thumb16 B) */
+	103: "R_ARM_THM_PC9",           /* PC relative & 0x1FE (Thumb16 B/B<cond>) */
+	104: "R_ARM_TLS_GD32",          /* PC-rel 32 bit for global dynamic thread local data */
+	105: "R_ARM_TLS_LDM32",         /* PC-rel 32 bit for local dynamic thread local data */
+	106: "R_ARM_TLS_LDO32",         /* 32 bit offset relative to TLS block */
+	107: "R_ARM_TLS_IE32",          /* PC-rel 32 bit for GOT entry of static TLS block offset */
+	108: "R_ARM_TLS_LE32",          /* 32 bit offset relative to static TLS block */
+	109: "R_ARM_TLS_LDO12",         /* 12 bit relative to TLS block (LDR, STR) */
+	110: "R_ARM_TLS_LE12",          /* 12 bit relative to static TLS block (LDR, STR) */
+	111: "R_ARM_TLS_IE12GP",        /* 12 bit GOT entry relative to GOT origin (LDR) */
+	160: "R_ARM_IRELATIVE",         /* Adjust indirectly by program base */
+	249: "R_ARM_RXPC25",            /* Obsolete */
+	250: "R_ARM_RSBREL32",          /* Obsolete */
+	251: "R_ARM_THM_RPC22",         /* Obsolete */
+	252: "R_ARM_RREL32",            /* Obsolete */
+	253: "R_ARM_RABS22",            /* Obsolete */
+	254: "R_ARM_RPC24",             /* Obsolete */
+	255: "R_ARM_RBASE",             /* Obsolete */
+}
+
+// RRISCVDecode map of RISC-V relocation types to friendly string
+var RRISCVDecode = map[uint32]string{
+	0:  "R_RISCV_NONE",          /* No reloc */
+	1:  "R_RISCV_32",            /* Direct 32 bit */
+	2:  "R_RISCV_64",            /* Direct 64 bit */
+	3:  "R_RISCV_RELATIVE",      /* Adjust by program base */
+	4:  "R_RISCV_COPY",          /* Copy symbol at runtime */
+	5:  "R_RISCV_JUMP_SLOT",     /* Create PLT entry */
+	6:  "R_RISCV_TLS_DTPMOD32",  /* ID of module containing symbol */
+	7:  "R_RISCV_TLS_DTPMOD64",  /* ID of module containing symbol */
+	8:  "R_RISCV_TLS_DTPREL32",  /* Offset in TLS block */
+	9:  "R_RISCV_TLS_DTPREL64",  /* Offset in TLS block */
+	10: "R_RISCV_TLS_TPREL32",   /* Offset in static TLS block */
+	11: "R_RISCV_TLS_TPREL64",   /* Offset in static TLS block */
+	12: "R_RISCV_TLSDESC",       /* TLS descriptor */
+	16: "R_RISCV_BRANCH",        /* PC-relative branch */
+	17: "R_RISCV_JAL",           /* PC-relative jump */
+	18: "R_RISCV_CALL",          /* PC-relative call */
+	19: "R_RISCV_CALL_PLT",      /* PC-relative call through PLT */
+	20: "R_RISCV_GOT_HI20",      /* PC-relative GOT reference */
+	21: "R_RISCV_TLS_GOT_HI20",  /* PC-relative TLS IE GOT offset */
+	22: "R_RISCV_TLS_GD_HI20",   /* PC-relative TLS GD reference */
+	23: "R_RISCV_PCREL_HI20",    /* PC-relative reference */
+	24: "R_RISCV_PCREL_LO12_I",  /* PC-relative reference */
+	25: "R_RISCV_PCREL_LO12_S",  /* PC-relative reference */
+	26: "R_RISCV_HI20",          /* Absolute address */
+	27: "R_RISCV_LO12_I",        /* Absolute address */
+	28: "R_RISCV_LO12_S",        /* Absolute address */
+	29: "R_RISCV_TPREL_HI20",    /* TLS LE thread offset */
+	30: "R_RISCV_TPREL_LO12_I",  /* TLS LE thread offset */
+	31: "R_RISCV_TPREL_LO12_S",  /* TLS LE thread offset */
+	32: "R_RISCV_TPREL_ADD",     /* TLS LE thread usage */
+	33: "R_RISCV_ADD8",          /* 8-bit label addition */
+	34: "R_RISCV_ADD16",         /* 16-bit label addition */
+	35: "R_RISCV_ADD32",         /* 32-bit label addition */
+	36: "R_RISCV_ADD64",         /* 64-bit label addition */
+	37: "R_RISCV_SUB8",          /* 8-bit label subtraction */
+	38: "R_RISCV_SUB16",         /* 16-bit label subtraction */
+	39: "R_RISCV_SUB32",         /* 32-bit label subtraction */
+	40: "R_RISCV_SUB64",         /* 64-bit label subtraction */
+	41: "R_RISCV_GNU_VTINHERIT", /* GNU C++ vtable hierarchy */
+	42: "R_RISCV_GNU_VTENTRY",   /* GNU C++ vtable member usage */
+	43: "R_RISCV_ALIGN",         /* Alignment statement */
+	44: "R_RISCV_RVC_BRANCH",    /* PC-relative branch offset */
+	45: "R_RISCV_RVC_JUMP",      /* PC-relative jump offset */
+	46: "R_RISCV_RVC_LUI",       /* Absolute address */
+	47: "R_RISCV_GPREL_I",       /* GP-relative reference */
+	48: "R_RISCV_GPREL_S",       /* GP-relative reference */
+	49: "R_RISCV_TPREL_I",       /* TP-relative TLS LE load */
+	50: "R_RISCV_TPREL_S",       /* TP-relative TLS LE store */
+	51: "R_RISCV_RELAX",         /* Instruction pair can be relaxed */
+	52: "R_RISCV_SUB6",          /* Local label subtraction */
+	53: "R_RISCV_SET6",          /* Local label assignment */
+	54: "R_RISCV_SET8",          /* Local label assignment */
+	55: "R_RISCV_SET16",         /* Local label assignment */
+	56: "R_RISCV_SET32",         /* Local label assignment */
+	57: "R_RISCV_32_PCREL",      /* 32-bit PC relative */
+	58: "R_RISCV_IRELATIVE",     /* Adjust indirectly by program base */
+	59: "R_RISCV_PLT32",         /* 32-bit relative offset to a function or its PLT entry */
+	60: "R_RISCV_SET_ULEB128",   /* Local label assignment, ULEB128 encoded */
+	61: "R_RISCV_SUB_ULEB128",   /* Local label subtraction, ULEB128 encoded */
+}
+
+// relocTypeDecode maps EMachine to the relocation type table of that architecture
+var relocTypeDecode = map[uint16]map[uint32]string{
+	0x03: R386Decode,
+	0x28: RARMDecode,
+	0x3E: RX86_64Decode,
+	0xB7: RAArch64Decode,
+	0xF3: RRISCVDecode,
+}
+
+// relativeRelocTypes the R_*_RELATIVE type of each architecture, given to the
+// entries of RELR tables, keyed by EMachine
+var relativeRelocTypes = map[uint16]uint32{
+	0x03: 8,    /* R_386_RELATIVE */
+	0x28: 23,   /* R_ARM_RELATIVE */
+	0x3E: 8,    /* R_X86_64_RELATIVE */
+	0xB7: 1027, /* R_AARCH64_RELATIVE */
+	0xF3: 3,    /* R_RISCV_RELATIVE */
+}
+
+// Relocation entry of a SHT_REL or SHT_RELA section, widened to the 64 bit
+// layout. Entries unpacked from APS2 and RELR sections use the same layout,
+// RELR entries being symbol-less R_*_RELATIVE relocations
+type Relocation struct {
+	ROffset   uint64 /* Location to apply the relocation to */
+	RInfo     uint64 /* Symbol index and relocation type */
+	RAddend   int64  /* Constant addend, only present in SHT_RELA */
+	HasAddend bool   /* Whether RAddend came from the entry or is implicit */
+	SymIndex  uint32 /* Index into the linked symbol table */
+	RType     uint32 /* Architecture specific relocation type */
+	Type      string /* Friendly name of RType */
+	Symbol    Symbol /* Symbol at SymIndex, zero valued for index 0 */
+	Section   string /* Name of the relocation section */
+	Target    string /* Name of the section the relocation applies to (sh_info) */
+}
+
+// RelocTypeName returns the friendly name of a relocation type for machine
+func RelocTypeName(machine uint16, rtype uint32) string {
+	if name, ok := relocTypeDecode[machine][rtype]; ok {
+		return name
+	}
+	return fmt.Sprintf("0x%X", rtype)
+}
+
+// FromBuffer64 initializes the Relocation given a buffer holding an Elf64_Rel
+// or, if rela is set, an Elf64_Rela
+func (r *Relocation) FromBuffer64(buf []byte, endianess uint8, rela bool) {
+	u64 := readu64Func(endianess)
+
+	r.ROffset = u64(buf, 0x00)
+	r.RInfo = u64(buf, 0x08)
+	if rela {
+		r.RAddend = int64(u64(buf, 0x10))
+		r.HasAddend = true
+	}
+	r.SymIndex = uint32(r.RInfo >> 32)
+	r.RType = uint32(r.RInfo)
+}
+
+// FromBuffer32 initializes the Relocation given a buffer holding an Elf32_Rel
+// or, if rela is set, an Elf32_Rela
+func (r *Relocation) FromBuffer32(buf []byte, endianess uint8, rela bool) {
+	u32 := readu32Func(endianess)
+
+	r.ROffset = uint64(u32(buf, 0x00))
+	r.RInfo = uint64(u32(buf, 0x04))
+	if rela {
+		r.RAddend = int64(int32(u32(buf, 0x08)))
+		r.HasAddend = true
+	}
+	r.SymIndex = uint32(r.RInfo >> 8)
+	r.RType = uint32(r.RInfo & 0xff)
+}
+
+// Relocations decodes every SHT_REL and SHT_RELA section, the Android packed
+// SHT_ANDROID_REL(A) sections, or failing those the DT_ANDROID_REL(A) tables,
+// and the RELR tables of SHT_RELR sections
+func (e ELF64) Relocations() ([]Relocation, error) {
+	return readRelocations(e)
+}
+
+// Relocations decodes every SHT_REL and SHT_RELA section, the Android packed
+// SHT_ANDROID_REL(A) sections, or failing those the DT_ANDROID_REL(A) tables,
+// and the RELR tables of SHT_RELR sections
+func (e ELF32) Relocations() ([]Relocation, error) {
+	return readRelocations(e)
+}
+
+func readRelocations(f File) ([]Relocation, error) {
+	sHead := f.SectionTable()
+	sections := f.SectionData()
+	header := f.Header()
+	symtabs := make(map[uint32][]Symbol)
+
+	relocs := make([]Relocation, 0)
+	packed := false
+	for i, h := range sHead {
+		var entries []Relocation
+		var entSize uint64 /* Fixed entry size, used to locate bad entries; 0 for packed formats */
+		var err error
+		data := sections[i].Data
+		switch h.SHType {
+		case SHTypeEncode["SHT_REL"], SHTypeEncode["SHT_RELA"]:
+			rela := h.SHType == SHTypeEncode["SHT_RELA"]
+			entSize = relocEntSize(f.Class(), rela)
+			if h.SHEntsize != 0 && h.SHEntsize < entSize {
+				return nil, formatErr(int64(h.SHOffset), "relocation section", nil, "sh_entsize 0x%X too small", h.SHEntsize)
+			}
+			if h.SHEntsize > entSize {
+				entSize = h.SHEntsize
+			}
+			for off := uint64(0); off+entSize <= uint64(len(data)); off += entSize {
+				var r Relocation
+				if f.Class() == CLASS32BIT {
+					r.FromBuffer32(data[off:], header.EIDATA, rela)
+				} else {
+					r.FromBuffer64(data[off:], header.EIDATA, rela)
+				}
+				entries = append(entries, r)
+			}
+		case SHTypeEncode["SHT_ANDROID_REL"], SHTypeEncode["SHT_ANDROID_RELA"]:
+			rela := h.SHType == SHTypeEncode["SHT_ANDROID_RELA"]
+			packed = true
+			if entries, err = decodeAPS2(data, f.Class(), rela, relocTargets(f), int64(h.SHOffset)); err != nil {
+				return nil, err
+			}
+		case SHTypeEncode["SHT_RELR"], SHTypeEncode["SHT_ANDROID_RELR"]:
+			rtype := relativeRelocTypes[header.EMachine]
+			for _, off := range decodeRELR(data, f.Class(), header.EIDATA) {
+				entries = append(entries, Relocation{ROffset: off, RInfo: uint64(rtype), RType: rtype})
+			}
+		default:
+			continue
+		}
+
+		// sh_link of 0 means the relocations reference no symbols
+		var symbols []Symbol
+		if h.SHLink != 0 {
+			var ok bool
+			if symbols, ok = symtabs[h.SHLink]; !ok {
+				if int(h.SHLink) >= len(sHead) {
+					return nil, formatErr(int64(h.SHOffset), "relocation section", nil, "sh_link 0x%X out of range", h.SHLink)
+				}
+				symbols, err = readSymbolTable(sHead, sections, int(h.SHLink), f.Class(), header.EIDATA)
+				if err != nil {
+					return nil, err
+				}
+				symtabs[h.SHLink] = symbols
+			}
+		}
+		var target string
+		if h.SHInfo != 0 && int(h.SHInfo) < len(sHead) {
+			target = sHead[h.SHInfo].SectionName
+		}
+
+		for j, r := range entries {
+			r.Type = RelocTypeName(header.EMachine, r.RType)
+			if r.SymIndex != 0 {
+				if int(r.SymIndex) >= len(symbols) {
+					return nil, formatErr(int64(h.SHOffset+uint64(j)*entSize), "relocation", nil, "symbol index 0x%X out of range", r.SymIndex)
+				}
+				r.Symbol = symbols[r.SymIndex]
+			}
+			r.Section = h.SectionName
+			r.Target = target
+			relocs = append(relocs, r)
+		}
+	}
+
+	if !packed {
+		entries, err := dynamicPackedRelocations(f)
+		if err != nil {
+			return nil, err
+		}
+		relocs = append(relocs, entries...)
+	}
+	return relocs, nil
+}
+
+// dynamicPackedRelocations decodes the APS2 tables DT_ANDROID_REL and
+// DT_ANDROID_RELA point at, for files without section headers describing them.
+// Symbols come from the dynamic symbol table and Section is the tag name
+func dynamicPackedRelocations(f File) ([]Relocation, error) {
+	table, err := readDynamic(f)
+	if err == ErrNoDynamic {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	space := newAddressSpace(f.Segments(), f.Class(), f.Header().EIDATA)
+	relocs := make([]Relocation, 0)
+	var dynsyms *dynamicSymbols
+	for _, tag := range []string{"DT_ANDROID_REL", "DT_ANDROID_RELA"} {
+		addr, ok := table.value(tag)
+		if !ok {
+			continue
+		}
+		size, _ := table.value(tag + "SZ")
+		if err := space.checkMapped(addr, size); err != nil {
+			return nil, err
+		}
+		data := make([]byte, size)
+		if _, err := space.ReadAt(data, int64(addr)); err != nil {
+			return nil, err
+		}
+		fileOff, _ := space.Offset(addr)
+		entries, err := decodeAPS2(data, f.Class(), tag == "DT_ANDROID_RELA", relocTargets(f), int64(fileOff))
+		if err != nil {
+			return nil, err
+		}
+		for _, r := range entries {
+			r.Type = RelocTypeName(f.Header().EMachine, r.RType)
+			if r.SymIndex != 0 {
+				if dynsyms == nil {
+					if dynsyms, err = newDynamicSymbols(f); err != nil {
+						return nil, err
+					}
+				}
+				if r.Symbol, err = dynsyms.symbol(r.SymIndex); err != nil {
+					return nil, err
+				}
+			}
+			r.Section = tag
+			relocs = append(relocs, r)
+		}
+	}
+	return relocs, nil
+}
+
+// APS2 group flags
+const (
+	aps2GroupedByInfo        = 0x1 /* All relocations of the group share r_info */
+	aps2GroupedByOffsetDelta = 0x2 /* All relocations of the group are the same distance apart */
+	aps2GroupedByAddend      = 0x4 /* All relocations of the group share the addend */
+	aps2GroupHasAddend       = 0x8 /* The group carries addends */
+)
+
+// relocTargets the number of pointer sized words in the file backed part of
+// the PT_LOAD segments, or of the allocated sections for a file without
+// segments, an upper bound on the relocations a file can need
+func relocTargets(f File) int64 {
+	word := 8
+	if f.Class() == CLASS32BIT {
+		word = 4
+	}
+	size := 0
+	for _, ph := range f.Segments() {
+		if ph.PType == PTypeEncode["PT_LOAD"] {
+			size += len(ph.Data)
+		}
+	}
+	if size == 0 {
+		sections := f.SectionData()
+		for i, h := range f.SectionTable() {
+			if i < len(sections) && h.SHFlags&SHFlagsEncode["SHF_ALLOC"] != 0 {
+				size += len(sections[i].Data)
+			}
+		}
+	}
+	return int64(size / word)
+}
+
+// decodeAPS2 unpacks the Android packed relocation format: the "APS2" magic
+// then SLEB128 values, the relocation count and initial offset, followed by
+// groups whose header says which fields are shared by the whole group and
+// which are given per relocation. Offsets and addends are deltas. A group can
+// describe many relocations in a few bytes, so the count is bounded by limit
+// rather than by the size of data
+func decodeAPS2(data []byte, class uint8, rela bool, limit int64, base int64) ([]Relocation, error) {
+	if len(data) < 4 || string(data[:4]) != "APS2" {
+		magic := data
+		if len(magic) > 4 {
+			magic = magic[:4]
+		}
+		return nil, formatErr(base, "packed relocations", nil, "bad magic %q", magic)
+	}
+	b := dwarfBuf{data: data, off: 4, name: "packed relocations"}
+	count := b.sleb()
+	if b.err == nil && (count < 0 || count > limit) {
+		return nil, formatErr(base+4, "packed relocations", nil, "bad relocation count %d", count)
+	}
+	offset := uint64(b.sleb())
+	relocs := make([]Relocation, 0)
+	var info, delta uint64
+	var addend int64
+	for int64(len(relocs)) < count && b.err == nil {
+		groupOff := b.off
+		size := b.sleb()
+		flags := b.sleb()
+		if flags&aps2GroupedByOffsetDelta != 0 {
+			delta = uint64(b.sleb())
+		}
+		if flags&aps2GroupedByInfo != 0 {
+			info = uint64(b.sleb())
+		}
+		if flags&aps2GroupHasAddend == 0 {
+			addend = 0
+		} else if flags&aps2GroupedByAddend != 0 {
+			addend += b.sleb()
+		}
+		if b.err == nil && (size <= 0 || size > count-int64(len(relocs))) {
+			return nil, formatErr(base+int64(groupOff), "packed relocations", nil, "bad group size %d", size)
+		}
+		for j := int64(0); j < size && b.err == nil; j++ {
+			if flags&aps2GroupedByOffsetDelta != 0 {
+				offset += delta
+			} else {
+				offset += uint64(b.sleb())
+			}
+			if flags&aps2GroupedByInfo == 0 {
+				info = uint64(b.sleb())
+			}
+			if flags&aps2GroupHasAddend != 0 && flags&aps2GroupedByAddend == 0 {
+				addend += b.sleb()
+			}
+			r := Relocation{ROffset: offset, RInfo: info, RAddend: addend, HasAddend: rela}
+			if class == CLASS32BIT {
+				r.SymIndex = uint32(info >> 8)
+				r.RType = uint32(info & 0xff)
+			} else {
+				r.SymIndex = uint32(info >> 32)
+				r.RType = uint32(info)
+			}
+			relocs = append(relocs, r)
+		}
+	}
+	if b.err != nil {
+		return nil, formatErr(base+int64(b.off), "packed relocations", b.err, "%d of %d relocations decoded", len(relocs), count)
+	}
+	return relocs, nil
+}
+
+// decodeRELR expands a RELR table into the offsets it relocates. An even word
+// is an offset to relocate, an odd word a bitmap of which of the following
+// 31 or 63 words, starting after the last offset, to relocate
+func decodeRELR(data []byte, class uint8, endianess uint8) []uint64 {
+	word := uint64(8)
+	read := readu64Func(endianess)
+	if class == CLASS32BIT {
+		word = 4
+		u32 := readu32Func(endianess)
+		read = func(buf []byte, off int) uint64 {
+			return uint64(u32(buf, off))
+		}
+	}
+	offsets := make([]uint64, 0)
+	var where uint64
+	for off := uint64(0); off+word <= uint64(len(data)); off += word {
+		entry := read(data, int(off))
+		if entry&1 == 0 {
+			offsets = append(offsets, entry)
+			where = entry + word
+			continue
+		}
+		for bit := uint64(1); bit < 8*word; bit++ {
+			if entry>>bit&1 != 0 {
+				offsets = append(offsets, where+(bit-1)*word)
+			}
+		}
+		where += (8*word - 1) * word
+	}
+	return offsets
+}
+
+func relocEntSize(class uint8, rela bool) uint64 {
+	switch {
+	case class == CLASS32BIT && rela:
+		return 0x0C
+	case class == CLASS32BIT:
+		return 0x08
+	case rela:
+		return 0x18
+	}
+	return 0x10
+}
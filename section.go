@@ -1,10 +1,60 @@
 package elf
 
+import (
+	"bytes"
+	"compress/zlib"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/klauspost/compress/zstd"
+)
+
+// CompressionTypeDecode maps uint32 to friendly name for ch_type
+var CompressionTypeDecode = map[uint32]string{
+	1:          "ELFCOMPRESS_ZLIB",   /* ZLIB/DEFLATE algorithm */
+	2:          "ELFCOMPRESS_ZSTD",   /* Zstandard algorithm */
+	0x60000000: "ELFCOMPRESS_LOOS",   /* Start of OS-specific */
+	0x6fffffff: "ELFCOMPRESS_HIOS",   /* End of OS-specific */
+	0x70000000: "ELFCOMPRESS_LOPROC", /* Start of processor-specific */
+	0x7fffffff: "ELFCOMPRESS_HIPROC", /* End of processor-specific */
+}
+
+// CompressionTypeEncode maps friendly name to uint32 for ch_type
+var CompressionTypeEncode = map[string]uint32{
+	"ELFCOMPRESS_ZLIB":   1,
+	"ELFCOMPRESS_ZSTD":   2,
+	"ELFCOMPRESS_LOOS":   0x60000000,
+	"ELFCOMPRESS_HIOS":   0x6fffffff,
+	"ELFCOMPRESS_LOPROC": 0x70000000,
+	"ELFCOMPRESS_HIPROC": 0x7fffffff,
+}
+
+// Size of the compression headers in bytes
+const (
+	chdr64Size = 24
+	chdr32Size = 12
+	zdebugSize = 12 /* "ZLIB" followed by the big endian uncompressed size */
+)
+
 // Section Stub
 type Section struct {
 	Data      []byte /* Actual bytes from the file */
 	startAddr uint64 /* Where in memory the code starts */
 	archBits  int    /* 32 or 64 bit */
+	flags     uint64 /* sh_flags, for SHF_COMPRESSED */
+	name      string /* Section name, for GNU .zdebug_ sections */
+	endianess uint8
+}
+
+// CompressionHeader Elf64_Chdr/Elf32_Chdr at the start of an SHF_COMPRESSED
+// section. GNU .zdebug_ sections are reported as ELFCOMPRESS_ZLIB
+type CompressionHeader struct {
+	ChType      uint32 /* Compression algorithm */
+	ChSize      uint64 /* Size of the uncompressed data */
+	ChAddralign uint64 /* Alignment of the uncompressed data */
+	Type        string /* Friendly name of ChType */
 }
 
 // Disassembles data into readable instructions
@@ -12,3 +62,94 @@ func (s Section) Disassemble() string {
 	dis := Disassembler{Buf: s.Data, StartAddr: s.startAddr, Arch: s.archBits}
 	return dis.Disasm()
 }
+
+// Compressed reports whether the section is SHF_COMPRESSED or a GNU .zdebug_ section
+func (s Section) Compressed() bool {
+	return s.flags&SHFlagsEncode["SHF_COMPRESSED"] != 0 || s.zdebug()
+}
+
+func (s Section) zdebug() bool {
+	return strings.HasPrefix(s.name, ".zdebug") && len(s.Data) >= zdebugSize && string(s.Data[:4]) == "ZLIB"
+}
+
+// CompressionHeader decodes the compression header of a compressed section
+func (s Section) CompressionHeader() (CompressionHeader, error) {
+	var ch CompressionHeader
+	switch {
+	case s.flags&SHFlagsEncode["SHF_COMPRESSED"] != 0:
+		if s.archBits == 32 {
+			if len(s.Data) < chdr32Size {
+				return ch, formatErr(int64(s.startAddr), "compression header", nil, "section of 0x%X bytes too small", len(s.Data))
+			}
+			u32 := readu32Func(s.endianess)
+			ch.ChType = u32(s.Data, 0x00)
+			ch.ChSize = uint64(u32(s.Data, 0x04))
+			ch.ChAddralign = uint64(u32(s.Data, 0x08))
+		} else {
+			if len(s.Data) < chdr64Size {
+				return ch, formatErr(int64(s.startAddr), "compression header", nil, "section of 0x%X bytes too small", len(s.Data))
+			}
+			ch.ChType = readu32Func(s.endianess)(s.Data, 0x00)
+			ch.ChSize = readu64Func(s.endianess)(s.Data, 0x08)
+			ch.ChAddralign = readu64Func(s.endianess)(s.Data, 0x10)
+		}
+	case s.zdebug():
+		ch.ChType = CompressionTypeEncode["ELFCOMPRESS_ZLIB"]
+		ch.ChSize = binary.BigEndian.Uint64(s.Data[4:zdebugSize])
+		ch.ChAddralign = 1
+	default:
+		return ch, ErrNotCompressed
+	}
+	ch.Type = CompressionTypeDecode[ch.ChType]
+	return ch, nil
+}
+
+// Decompressed returns the uncompressed section contents, decompressing
+// SHF_COMPRESSED and .zdebug_ sections on each call. Data is returned as is
+// for other sections
+func (s Section) Decompressed() ([]byte, error) {
+	if !s.Compressed() {
+		return s.Data, nil
+	}
+	ch, err := s.CompressionHeader()
+	if err != nil {
+		return nil, err
+	}
+	payload := s.Data[chdr64Size:]
+	if s.zdebug() {
+		payload = s.Data[zdebugSize:]
+	} else if s.archBits == 32 {
+		payload = s.Data[chdr32Size:]
+	}
+
+	// ch_size comes from the file, grow the output as data arrives rather
+	// than trusting it for the allocation
+	var r io.Reader
+	switch ch.Type {
+	case "ELFCOMPRESS_ZLIB":
+		zr, err := zlib.NewReader(bytes.NewReader(payload))
+		if err != nil {
+			return nil, formatErr(int64(s.startAddr), "compressed section", err, "bad zlib stream")
+		}
+		defer zr.Close()
+		r = zr
+	case "ELFCOMPRESS_ZSTD":
+		zr, err := zstd.NewReader(bytes.NewReader(payload), zstd.WithDecoderConcurrency(1))
+		if err != nil {
+			return nil, formatErr(int64(s.startAddr), "compressed section", err, "bad zstd stream")
+		}
+		defer zr.Close()
+		r = zr
+	default:
+		return nil, fmt.Errorf("elf: ch_type 0x%X: %w", ch.ChType, ErrUnsupportedCompression)
+	}
+	var out bytes.Buffer
+	n, err := io.Copy(&out, io.LimitReader(r, int64(ch.ChSize)))
+	if err != nil {
+		return nil, formatErr(int64(s.startAddr), "compressed section", err, "decompressing 0x%X bytes", ch.ChSize)
+	}
+	if uint64(n) != ch.ChSize {
+		return nil, formatErr(int64(s.startAddr), "compressed section", nil, "decompressed 0x%X bytes, ch_size is 0x%X", n, ch.ChSize)
+	}
+	return out.Bytes(), nil
+}
@@ -21,8 +21,16 @@ var SHTypeDecode = map[uint32]string{
 	0x10:       "SHT_PREINIT_ARRAY", /* Array of pre-constructors */
 	0x11:       "SHT_GROUP",         /* Section group */
 	0x12:       "SHT_SYMTAB_SHNDX",  /* Extended section indices */
-	0x13:       "SHT_NUM",           /* Number of defined types. */
+	0x13:       "SHT_RELR",          /* RELR relative relocations */
+	0x14:       "SHT_NUM",           /* Number of defined types. */
 	0x60000000: "SHT_LOOS",          /* Start OS-specific. */
+	0x60000001: "SHT_ANDROID_REL",   /* Android APS2 packed relocations, no addends */
+	0x60000002: "SHT_ANDROID_RELA",  /* Android APS2 packed relocations with addends */
+	0x6fffff00: "SHT_ANDROID_RELR",  /* RELR relative relocations, Android's pre-standard type */
+	0x6ffffff6: "SHT_GNU_HASH",      /* GNU-style hash table */
+	0x6ffffffd: "SHT_GNU_verdef",    /* Version definition section */
+	0x6ffffffe: "SHT_GNU_verneed",   /* Version needs section */
+	0x6fffffff: "SHT_GNU_versym",    /* Version symbol table */
 }
 
 // SHTypeEncode encodes friendly shtype strings to uint32 value
@@ -44,8 +52,16 @@ var SHTypeEncode = map[string]uint32{
 	"SHT_PREINIT_ARRAY": 0x10,
 	"SHT_GROUP":         0x11,
 	"SHT_SYMTAB_SHNDX":  0x12,
-	"SHT_NUM":           0x13,
+	"SHT_RELR":          0x13,
+	"SHT_NUM":           0x14,
 	"SHT_LOOS":          0x60000000,
+	"SHT_ANDROID_REL":   0x60000001,
+	"SHT_ANDROID_RELA":  0x60000002,
+	"SHT_ANDROID_RELR":  0x6fffff00,
+	"SHT_GNU_HASH":      0x6ffffff6,
+	"SHT_GNU_verdef":    0x6ffffffd,
+	"SHT_GNU_verneed":   0x6ffffffe,
+	"SHT_GNU_versym":    0x6fffffff,
 }
 
 // SHFlagsDecode maps uint64 to friendly name for shflags
@@ -61,6 +77,7 @@ var SHFlagsDecode = map[uint64]string{
 	0x100:      "SHF_OS_NONCONFORMING", /* Non-standard OS specific handling required */
 	0x200:      "SHF_GROUP",            /* Section is member of a group */
 	0x400:      "SHF_TLS",              /* Section hold thread-local data */
+	0x800:      "SHF_COMPRESSED",       /* Section with compressed data */
 	0x0ff00000: "SHF_MASKOS",           /* OS-specific */
 	0xf0000000: "SHF_MASKPROC",         /* Processor-specific */
 	0x4000000:  "SHF_ORDERED",          /* Special ordering requirement (Solaris) */
@@ -80,6 +97,7 @@ var SHFlagsEncode = map[string]uint64{
 	"SHF_OS_NONCONFORMING": 0x100,
 	"SHF_GROUP":            0x200,
 	"SHF_TLS":              0x400,
+	"SHF_COMPRESSED":       0x800,
 	"SHF_MASKOS":           0x0ff00000,
 	"SHF_MASKPROC":         0xf0000000,
 	"SHF_ORDERED":          0x4000000,
@@ -127,21 +145,87 @@ func (h *SectionHeader64) FromBuffer(buf []byte, endianess uint8) {
 }
 
 func (h *SectionHeader64) readFlags() {
-	if h.SHFlags == 0 {
-		h.SectionFlags = make([]string, 1)
-		h.SectionFlags[0] = SHFlagsDecode[0]
-		return
+	h.SectionFlags = decodeSHFlags(h.SHFlags)
+}
+
+// SectionHeader32 32 bit section header struct
+type SectionHeader32 struct {
+	SHName       uint32   /* index of shstrtab string - name */
+	SHType       uint32   /* Identifies the type of this header. */
+	SHFlags      uint32   /* Identifies the attributes of the section. */
+	SHAddr       uint32   /* Virtual address of the section in memory. */
+	SHOffset     uint32   /* Offset of the section in the file image. */
+	SHSize       uint32   /* Size in bytes of the section in the file image. */
+	SHLink       uint32   /* Section index of an associated section. */
+	SHInfo       uint32   /* Extra information about the section. */
+	SHAddrAlign  uint32   /* Required alignment of the section. */
+	SHEntsize    uint32   /* Size, in bytes, of each entry */
+	HeaderType   string   /* Friendly name for shtype */
+	SectionFlags []string /* Friendly name for section flag */
+	SectionName  string   /* Name of section */
+}
+
+// FromBuffer initializes the SectionHeader32 given a buffer of the size of the
+// section header
+func (h *SectionHeader32) FromBuffer(buf []byte, endianess uint8) {
+	u32 := readu32Func(endianess)
+
+	h.SHName = u32(buf, 0x00)
+	h.SHType = u32(buf, 0x04)
+	h.SHFlags = u32(buf, 0x08)
+	h.SHAddr = u32(buf, 0x0C)
+	h.SHOffset = u32(buf, 0x10)
+	h.SHSize = u32(buf, 0x14)
+	h.SHLink = u32(buf, 0x18)
+	h.SHInfo = u32(buf, 0x1C)
+	h.SHAddrAlign = u32(buf, 0x20)
+	h.SHEntsize = u32(buf, 0x24)
+	h.HeaderType = SHTypeDecode[h.SHType]
+	if h.HeaderType == "" {
+		h.HeaderType = "SHT_UNKNOWN"
+	}
+	h.readFlags()
+}
+
+func (h *SectionHeader32) readFlags() {
+	h.SectionFlags = decodeSHFlags(uint64(h.SHFlags))
+}
+
+func decodeSHFlags(shflags uint64) []string {
+	if shflags == 0 {
+		flags := make([]string, 1)
+		flags[0] = SHFlagsDecode[0]
+		return flags
 	}
-	h.SectionFlags = make([]string, 0, 64)
+	flags := make([]string, 0, 64)
 	for i := 0; i < 64; i++ {
 		var bitmask uint64 = 1 << i
-		present := (h.SHFlags & bitmask) != 0
+		present := (shflags & bitmask) != 0
 		if present {
 			flagStr := SHFlagsDecode[bitmask]
 			if flagStr == "" {
 				flagStr = fmt.Sprintf("0x%X", bitmask)
 			}
-			h.SectionFlags = append(h.SectionFlags, flagStr)
+			flags = append(flags, flagStr)
 		}
 	}
+	return flags
+}
+
+func (h SectionHeader32) widen() SectionHeader64 {
+	return SectionHeader64{
+		SHName:       h.SHName,
+		SHType:       h.SHType,
+		SHFlags:      uint64(h.SHFlags),
+		SHAddr:       uint64(h.SHAddr),
+		SHOffset:     uint64(h.SHOffset),
+		SHSize:       uint64(h.SHSize),
+		SHLink:       h.SHLink,
+		SHInfo:       h.SHInfo,
+		SHAddrAlign:  uint64(h.SHAddrAlign),
+		SHEntsize:    uint64(h.SHEntsize),
+		HeaderType:   h.HeaderType,
+		SectionFlags: h.SectionFlags,
+		SectionName:  h.SectionName,
+	}
 }
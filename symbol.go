@@ -0,0 +1,326 @@
+package elf
+
+import "sort"
+
+const (
+	symbol64Size = 0x18
+	symbol32Size = 0x10
+)
+
+// STTypeDecode map of the low nibble of st_info to friendly string
+var STTypeDecode = map[uint8]string{
+	0x0: "STT_NOTYPE",    /* Symbol type is unspecified */
+	0x1: "STT_OBJECT",    /* Symbol is a data object */
+	0x2: "STT_FUNC",      /* Symbol is a code object */
+	0x3: "STT_SECTION",   /* Symbol associated with a section */
+	0x4: "STT_FILE",      /* Symbol's name is file name */
+	0x5: "STT_COMMON",    /* Symbol is a common data object */
+	0x6: "STT_TLS",       /* Symbol is thread-local data object */
+	0xA: "STT_GNU_IFUNC", /* Symbol is an indirect code object */
+	0xD: "STT_LOPROC",    /* Start of processor-specific */
+	0xF: "STT_HIPROC",    /* End of processor-specific */
+}
+
+// STTypeEncode map of friendly string to symbol type
+var STTypeEncode = map[string]uint8{
+	"STT_NOTYPE":    0x0,
+	"STT_OBJECT":    0x1,
+	"STT_FUNC":      0x2,
+	"STT_SECTION":   0x3,
+	"STT_FILE":      0x4,
+	"STT_COMMON":    0x5,
+	"STT_TLS":       0x6,
+	"STT_GNU_IFUNC": 0xA,
+	"STT_LOPROC":    0xD,
+	"STT_HIPROC":    0xF,
+}
+
+// STBindDecode map of the high nibble of st_info to friendly string
+var STBindDecode = map[uint8]string{
+	0x0: "STB_LOCAL",      /* Local symbol */
+	0x1: "STB_GLOBAL",     /* Global symbol */
+	0x2: "STB_WEAK",       /* Weak symbol */
+	0xA: "STB_GNU_UNIQUE", /* Unique symbol */
+	0xD: "STB_LOPROC",     /* Start of processor-specific */
+	0xF: "STB_HIPROC",     /* End of processor-specific */
+}
+
+// STBindEncode map of friendly string to symbol binding
+var STBindEncode = map[string]uint8{
+	"STB_LOCAL":      0x0,
+	"STB_GLOBAL":     0x1,
+	"STB_WEAK":       0x2,
+	"STB_GNU_UNIQUE": 0xA,
+	"STB_LOPROC":     0xD,
+	"STB_HIPROC":     0xF,
+}
+
+// STVisibilityDecode map of the low bits of st_other to friendly string
+var STVisibilityDecode = map[uint8]string{
+	0x0: "STV_DEFAULT",   /* Default symbol visibility rules */
+	0x1: "STV_INTERNAL",  /* Processor specific hidden class */
+	0x2: "STV_HIDDEN",    /* Sym unavailable in other modules */
+	0x3: "STV_PROTECTED", /* Not preemptible, not exported */
+}
+
+// STVisibilityEncode map of friendly string to symbol visibility
+var STVisibilityEncode = map[string]uint8{
+	"STV_DEFAULT":   0x0,
+	"STV_INTERNAL":  0x1,
+	"STV_HIDDEN":    0x2,
+	"STV_PROTECTED": 0x3,
+}
+
+// SHNDecode map of reserved section indexes to friendly string
+var SHNDecode = map[uint16]string{
+	0x0000: "SHN_UNDEF",  /* Undefined section */
+	0xfff1: "SHN_ABS",    /* Associated symbol is absolute */
+	0xfff2: "SHN_COMMON", /* Associated symbol is common */
+	0xffff: "SHN_XINDEX", /* Index is in extra table */
+}
+
+// SHNEncode map of friendly string to reserved section indexes
+var SHNEncode = map[string]uint16{
+	"SHN_UNDEF":  0x0000,
+	"SHN_ABS":    0xfff1,
+	"SHN_COMMON": 0xfff2,
+	"SHN_XINDEX": 0xffff,
+}
+
+// Symbol entry of a symbol table, widened to the 64 bit layout
+type Symbol struct {
+	STName     uint32 /* Index of the symbol name in the linked string table */
+	STInfo     uint8  /* Type (low nibble) and binding (high nibble) */
+	STOther    uint8  /* Visibility (low two bits) */
+	STShndx    uint16 /* Index of the section the symbol is defined in */
+	STValue    uint64 /* Value of the symbol, usually an address */
+	STSize     uint64 /* Size of the object the symbol describes */
+	Name       string /* Name of the symbol */
+	Type       string /* Friendly name of the symbol type */
+	Binding    string /* Friendly name of the symbol binding */
+	Visibility string /* Friendly name of the symbol visibility */
+	Section    string /* Name of the section in STShndx, or the SHN_ name */
+
+	SectionIndex uint32 /* STShndx, or the entry of SHT_SYMTAB_SHNDX when STShndx is SHN_XINDEX */
+
+	// Only set for dynamic symbols of files with a SHT_GNU_versym section
+	VersionIndex  uint16 /* Index into the version definitions or needs */
+	VersionHidden bool   /* Hidden versions can't be linked against, e.g. memcpy@GLIBC_2.2.5 */
+	Version       string /* Name of the version, e.g. GLIBC_2.14 */
+	Library       string /* File the version is needed from, for imported symbols */
+}
+
+// FromBuffer64 initializes the Symbol given a buffer holding an Elf64_Sym
+func (s *Symbol) FromBuffer64(buf []byte, endianess uint8) {
+	u16 := readu16Func(endianess)
+	u32 := readu32Func(endianess)
+	u64 := readu64Func(endianess)
+
+	s.STName = u32(buf, 0x00)
+	s.STInfo = readu8(buf, 0x04)
+	s.STOther = readu8(buf, 0x05)
+	s.STShndx = u16(buf, 0x06)
+	s.STValue = u64(buf, 0x08)
+	s.STSize = u64(buf, 0x10)
+	s.decode()
+}
+
+// FromBuffer32 initializes the Symbol given a buffer holding an Elf32_Sym
+func (s *Symbol) FromBuffer32(buf []byte, endianess uint8) {
+	u16 := readu16Func(endianess)
+	u32 := readu32Func(endianess)
+
+	s.STName = u32(buf, 0x00)
+	s.STValue = uint64(u32(buf, 0x04))
+	s.STSize = uint64(u32(buf, 0x08))
+	s.STInfo = readu8(buf, 0x0C)
+	s.STOther = readu8(buf, 0x0D)
+	s.STShndx = u16(buf, 0x0E)
+	s.decode()
+}
+
+func (s *Symbol) decode() {
+	s.Type = STTypeDecode[s.STInfo&0xf]
+	s.Binding = STBindDecode[s.STInfo>>4]
+	s.Visibility = STVisibilityDecode[s.STOther&0x3]
+	s.SectionIndex = uint32(s.STShndx)
+}
+
+// Symbols returns the entries of the .symtab section, including the null
+// symbol at index 0 so that indexes match those used by relocations
+func (e ELF64) Symbols() ([]Symbol, error) {
+	return readSymbols(e.SectionHeaders, e.Sections, SHTypeEncode["SHT_SYMTAB"], CLASS64BIT, e.FileHeader.EIDATA)
+}
+
+// DynamicSymbols returns the entries of the .dynsym section, including the
+// null symbol at index 0
+func (e ELF64) DynamicSymbols() ([]Symbol, error) {
+	return readSymbols(e.SectionHeaders, e.Sections, SHTypeEncode["SHT_DYNSYM"], CLASS64BIT, e.FileHeader.EIDATA)
+}
+
+// SymbolByName finds a symbol by name, looking in .symtab then .dynsym
+func (e ELF64) SymbolByName(name string) (Symbol, error) {
+	return symbolByName(e, name)
+}
+
+// SymbolByAddr finds the function or object symbol covering addr, looking in
+// .symtab then .dynsym
+func (e ELF64) SymbolByAddr(addr uint64) (Symbol, error) {
+	return symbolByAddr(e, addr)
+}
+
+// Symbols returns the entries of the .symtab section, including the null
+// symbol at index 0
+func (e ELF32) Symbols() ([]Symbol, error) {
+	return readSymbols(e.SectionTable(), e.Sections, SHTypeEncode["SHT_SYMTAB"], CLASS32BIT, e.FileHeader.EIDATA)
+}
+
+// DynamicSymbols returns the entries of the .dynsym section, including the
+// null symbol at index 0
+func (e ELF32) DynamicSymbols() ([]Symbol, error) {
+	return readSymbols(e.SectionTable(), e.Sections, SHTypeEncode["SHT_DYNSYM"], CLASS32BIT, e.FileHeader.EIDATA)
+}
+
+// SymbolByName finds a symbol by name, looking in .symtab then .dynsym
+func (e ELF32) SymbolByName(name string) (Symbol, error) {
+	return symbolByName(e, name)
+}
+
+// SymbolByAddr finds the function or object symbol covering addr, looking in
+// .symtab then .dynsym
+func (e ELF32) SymbolByAddr(addr uint64) (Symbol, error) {
+	return symbolByAddr(e, addr)
+}
+
+// readSymbols decodes the first section of type shType as a symbol table
+func readSymbols(sHead []SectionHeader64, sections []Section, shType uint32, class uint8, endianess uint8) ([]Symbol, error) {
+	for i, h := range sHead {
+		if h.SHType == shType {
+			return readSymbolTable(sHead, sections, i, class, endianess)
+		}
+	}
+	return nil, ErrNoSymbols
+}
+
+// readSymbolTable decodes the symbol table held in section index
+func readSymbolTable(sHead []SectionHeader64, sections []Section, index int, class uint8, endianess uint8) ([]Symbol, error) {
+	h := sHead[index]
+	entSize := uint64(symbol64Size)
+	if class == CLASS32BIT {
+		entSize = symbol32Size
+	}
+	if h.SHEntsize != 0 && h.SHEntsize < entSize {
+		return nil, formatErr(int64(h.SHOffset), "symbol table", nil, "sh_entsize 0x%X too small", h.SHEntsize)
+	}
+	if h.SHEntsize > entSize {
+		entSize = h.SHEntsize
+	}
+	if int(h.SHLink) >= len(sections) {
+		return nil, formatErr(int64(h.SHOffset), "symbol table", nil, "sh_link 0x%X out of range", h.SHLink)
+	}
+	data := sections[index].Data
+	strtab := sections[h.SHLink].Data
+
+	// Section indexes that don't fit st_shndx are in a parallel table of
+	// 32 bit words linked to this symbol table
+	var shndx []byte
+	for i, sh := range sHead {
+		if sh.SHType == SHTypeEncode["SHT_SYMTAB_SHNDX"] && int(sh.SHLink) == index {
+			shndx = sections[i].Data
+		}
+	}
+	u32 := readu32Func(endianess)
+
+	symbols := make([]Symbol, uint64(len(data))/entSize)
+	for i := range symbols {
+		buf := data[uint64(i)*entSize:]
+		if class == CLASS32BIT {
+			symbols[i].FromBuffer32(buf, endianess)
+		} else {
+			symbols[i].FromBuffer64(buf, endianess)
+		}
+		name, ok := readCStr(strtab, int(symbols[i].STName))
+		if !ok {
+			return nil, formatErr(int64(h.SHOffset+uint64(i)*entSize), "symbol", nil, "st_name 0x%X out of range", symbols[i].STName)
+		}
+		symbols[i].Name = name
+		if symbols[i].STShndx == SHNEncode["SHN_XINDEX"] {
+			if (i+1)*4 > len(shndx) {
+				return nil, formatErr(int64(h.SHOffset+uint64(i)*entSize), "symbol", nil, "SHN_XINDEX without SHT_SYMTAB_SHNDX entry")
+			}
+			symbols[i].SectionIndex = u32(shndx, i*4)
+			if int(symbols[i].SectionIndex) < len(sHead) {
+				symbols[i].Section = sHead[symbols[i].SectionIndex].SectionName
+			}
+			continue
+		}
+		symbols[i].Section = SHNDecode[symbols[i].STShndx]
+		if symbols[i].Section == "" && int(symbols[i].STShndx) < len(sHead) {
+			symbols[i].Section = sHead[symbols[i].STShndx].SectionName
+		}
+	}
+	if h.SHType == SHTypeEncode["SHT_DYNSYM"] {
+		if err := applyVersions(sHead, sections, index, symbols, endianess); err != nil {
+			return nil, err
+		}
+	}
+	return symbols, nil
+}
+
+func allSymbols(f File) [][]Symbol {
+	var tables [][]Symbol
+	if syms, err := f.Symbols(); err == nil {
+		tables = append(tables, syms)
+	}
+	if syms, err := f.DynamicSymbols(); err == nil {
+		tables = append(tables, syms)
+	}
+	return tables
+}
+
+func symbolByName(f File, name string) (Symbol, error) {
+	for _, syms := range allSymbols(f) {
+		for _, s := range syms {
+			if s.Name == name && s.STShndx != SHNEncode["SHN_UNDEF"] {
+				return s, nil
+			}
+		}
+	}
+	// Fall back to undefined references, e.g. imports in .dynsym
+	for _, syms := range allSymbols(f) {
+		for _, s := range syms {
+			if s.Name == name {
+				return s, nil
+			}
+		}
+	}
+	return Symbol{}, ErrSymbolNotFound
+}
+
+func symbolByAddr(f File, addr uint64) (Symbol, error) {
+	for _, syms := range allSymbols(f) {
+		candidates := make([]Symbol, 0, len(syms))
+		for _, s := range syms {
+			if s.STShndx == SHNEncode["SHN_UNDEF"] || s.STValue > addr {
+				continue
+			}
+			if s.Type != "STT_FUNC" && s.Type != "STT_OBJECT" && s.Type != "STT_NOTYPE" && s.Type != "STT_GNU_IFUNC" {
+				continue
+			}
+			candidates = append(candidates, s)
+		}
+		// Prefer the closest symbol, and sized symbols over unsized ones
+		sort.SliceStable(candidates, func(i, j int) bool {
+			if candidates[i].STValue != candidates[j].STValue {
+				return candidates[i].STValue > candidates[j].STValue
+			}
+			return candidates[i].STSize > candidates[j].STSize
+		})
+		for _, s := range candidates {
+			if addr < s.STValue+s.STSize || (s.STSize == 0 && addr == s.STValue) {
+				return s, nil
+			}
+		}
+	}
+	return Symbol{}, ErrSymbolNotFound
+}
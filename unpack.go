@@ -1,31 +1,35 @@
 package elf
 
 import (
+	"bytes"
 	"encoding/binary"
-	"fmt"
-	"os"
+	"io"
 )
 
-func checkedRead(f *os.File, buf []byte, offset int64) {
-	_, err := f.ReadAt(buf, offset)
+func checkedRead(r io.ReaderAt, buf []byte, offset int64, structure string) error {
+	if len(buf) == 0 {
+		return nil
+	}
+	_, err := r.ReadAt(buf, offset)
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
 	if err != nil {
-		fmt.Fprint(os.Stderr, err)
-		os.Exit(1)
+		return formatErr(offset, structure, err, "reading 0x%X bytes", len(buf))
 	}
+	return nil
 }
 
-func readStr(file *os.File, offset int) string {
-	buf := make([]byte, 1)
-	var name string
-	// I'd rather not rely on finding a nul byte, but this seems to be the only way
-	for i := 0; ; i++ {
-		checkedRead(file, buf, int64(offset+i))
-		if buf[0] == 0 {
-			break
-		}
-		name += string(buf[0])
+// readCStr returns the nul terminated string starting at offset in buf
+func readCStr(buf []byte, offset int) (string, bool) {
+	if offset < 0 || offset >= len(buf) {
+		return "", false
+	}
+	end := bytes.IndexByte(buf[offset:], 0)
+	if end < 0 {
+		return "", false
 	}
-	return name
+	return string(buf[offset : offset+end]), true
 }
 
 func readu8(buf []byte, offset int) uint8 {
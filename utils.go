@@ -1,7 +0,0 @@
-package elf
-
-func assert(condition bool, msg string) {
-	if !condition {
-		panic(msg)
-	}
-}
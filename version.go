@@ -0,0 +1,252 @@
+package elf
+
+const (
+	versymLocal  = 0      /* Symbol is local, not available outside the object */
+	versymGlobal = 1      /* Symbol is global, unversioned */
+	versymHidden = 0x8000 /* Symbol is hidden and can't be linked against */
+)
+
+// VerFlagsDecode map of vd_flags and vna_flags bits to friendly string
+var VerFlagsDecode = map[uint64]string{
+	0x1: "VER_FLG_BASE", /* Version definition of the file itself */
+	0x2: "VER_FLG_WEAK", /* Weak version identifier */
+}
+
+// VersionNeed entry of a SHT_GNU_verneed section, the versions required from one file
+type VersionNeed struct {
+	VNVersion uint16           /* Version of the structure, always 1 */
+	VNCnt     uint16           /* Number of associated Vernaux entries */
+	VNFile    uint32           /* Offset of the file name in the string table */
+	File      string           /* Name of the file the versions are needed from */
+	Versions  []VersionNeedAux /* The versions needed */
+}
+
+// VersionNeedAux entry naming a version required from a file
+type VersionNeedAux struct {
+	VNAHash  uint32   /* Hash of the version name */
+	VNAFlags uint16   /* Version flags, see VerFlagsDecode */
+	VNAOther uint16   /* Version index, as used by SHT_GNU_versym */
+	VNAName  uint32   /* Offset of the version name in the string table */
+	Name     string   /* Version name, e.g. GLIBC_2.14 */
+	Flags    []string /* Friendly names of VNAFlags */
+}
+
+// VersionDef entry of a SHT_GNU_verdef section, a version defined by this file
+type VersionDef struct {
+	VDVersion uint16   /* Version of the structure, always 1 */
+	VDFlags   uint16   /* Version flags, see VerFlagsDecode */
+	VDNdx     uint16   /* Version index, as used by SHT_GNU_versym */
+	VDCnt     uint16   /* Number of associated Verdaux entries */
+	VDHash    uint32   /* Hash of the version name */
+	Name      string   /* Version name, from the first Verdaux entry */
+	Parents   []string /* Names of the versions this one inherits from */
+	Flags     []string /* Friendly names of VDFlags */
+}
+
+// VersionNeeds decodes the SHT_GNU_verneed section
+func (e ELF64) VersionNeeds() ([]VersionNeed, error) {
+	return readVersionNeeds(e.SectionHeaders, e.Sections, e.FileHeader.EIDATA)
+}
+
+// VersionDefs decodes the SHT_GNU_verdef section
+func (e ELF64) VersionDefs() ([]VersionDef, error) {
+	return readVersionDefs(e.SectionHeaders, e.Sections, e.FileHeader.EIDATA)
+}
+
+// VersionNeeds decodes the SHT_GNU_verneed section
+func (e ELF32) VersionNeeds() ([]VersionNeed, error) {
+	return readVersionNeeds(e.SectionTable(), e.Sections, e.FileHeader.EIDATA)
+}
+
+// VersionDefs decodes the SHT_GNU_verdef section
+func (e ELF32) VersionDefs() ([]VersionDef, error) {
+	return readVersionDefs(e.SectionTable(), e.Sections, e.FileHeader.EIDATA)
+}
+
+func findSection(sHead []SectionHeader64, shType uint32) int {
+	for i, h := range sHead {
+		if h.SHType == shType {
+			return i
+		}
+	}
+	return -1
+}
+
+func linkedStrtab(sHead []SectionHeader64, sections []Section, index int) ([]byte, error) {
+	h := sHead[index]
+	if int(h.SHLink) >= len(sections) {
+		return nil, formatErr(int64(h.SHOffset), h.HeaderType, nil, "sh_link 0x%X out of range", h.SHLink)
+	}
+	return sections[h.SHLink].Data, nil
+}
+
+func readVersionNeeds(sHead []SectionHeader64, sections []Section, endianess uint8) ([]VersionNeed, error) {
+	index := findSection(sHead, SHTypeEncode["SHT_GNU_verneed"])
+	if index < 0 {
+		return []VersionNeed{}, nil
+	}
+	strtab, err := linkedStrtab(sHead, sections, index)
+	if err != nil {
+		return nil, err
+	}
+	u16 := readu16Func(endianess)
+	u32 := readu32Func(endianess)
+	data := sections[index].Data
+	base := int64(sHead[index].SHOffset)
+
+	needs := make([]VersionNeed, 0)
+	for off := uint64(0); ; {
+		if off+16 > uint64(len(data)) {
+			return nil, formatErr(base+int64(off), "Elf_Verneed", nil, "entry past end of section")
+		}
+		var vn VersionNeed
+		vn.VNVersion = u16(data, int(off))
+		vn.VNCnt = u16(data, int(off+2))
+		vn.VNFile = u32(data, int(off+4))
+		aux := u32(data, int(off+8))
+		next := u32(data, int(off+12))
+		vn.File, _ = readCStr(strtab, int(vn.VNFile))
+
+		vn.Versions = make([]VersionNeedAux, 0, vn.VNCnt)
+		auxOff := off + uint64(aux)
+		for i := 0; i < int(vn.VNCnt); i++ {
+			if auxOff+16 > uint64(len(data)) {
+				return nil, formatErr(base+int64(auxOff), "Elf_Vernaux", nil, "entry past end of section")
+			}
+			var va VersionNeedAux
+			va.VNAHash = u32(data, int(auxOff))
+			va.VNAFlags = u16(data, int(auxOff+4))
+			va.VNAOther = u16(data, int(auxOff+6))
+			va.VNAName = u32(data, int(auxOff+8))
+			va.Name, _ = readCStr(strtab, int(va.VNAName))
+			va.Flags = decodeBits(uint64(va.VNAFlags), VerFlagsDecode)
+			vn.Versions = append(vn.Versions, va)
+
+			auxNext := u32(data, int(auxOff+12))
+			if auxNext == 0 {
+				break
+			}
+			auxOff += uint64(auxNext)
+		}
+		needs = append(needs, vn)
+
+		if next == 0 {
+			break
+		}
+		off += uint64(next)
+	}
+	return needs, nil
+}
+
+func readVersionDefs(sHead []SectionHeader64, sections []Section, endianess uint8) ([]VersionDef, error) {
+	index := findSection(sHead, SHTypeEncode["SHT_GNU_verdef"])
+	if index < 0 {
+		return []VersionDef{}, nil
+	}
+	strtab, err := linkedStrtab(sHead, sections, index)
+	if err != nil {
+		return nil, err
+	}
+	u16 := readu16Func(endianess)
+	u32 := readu32Func(endianess)
+	data := sections[index].Data
+	base := int64(sHead[index].SHOffset)
+
+	defs := make([]VersionDef, 0)
+	for off := uint64(0); ; {
+		if off+20 > uint64(len(data)) {
+			return nil, formatErr(base+int64(off), "Elf_Verdef", nil, "entry past end of section")
+		}
+		var vd VersionDef
+		vd.VDVersion = u16(data, int(off))
+		vd.VDFlags = u16(data, int(off+2))
+		vd.VDNdx = u16(data, int(off+4))
+		vd.VDCnt = u16(data, int(off+6))
+		vd.VDHash = u32(data, int(off+8))
+		aux := u32(data, int(off+12))
+		next := u32(data, int(off+16))
+		vd.Flags = decodeBits(uint64(vd.VDFlags), VerFlagsDecode)
+
+		vd.Parents = make([]string, 0)
+		auxOff := off + uint64(aux)
+		for i := 0; i < int(vd.VDCnt); i++ {
+			if auxOff+8 > uint64(len(data)) {
+				return nil, formatErr(base+int64(auxOff), "Elf_Verdaux", nil, "entry past end of section")
+			}
+			name, _ := readCStr(strtab, int(u32(data, int(auxOff))))
+			if i == 0 {
+				vd.Name = name
+			} else {
+				vd.Parents = append(vd.Parents, name)
+			}
+			auxNext := u32(data, int(auxOff+4))
+			if auxNext == 0 {
+				break
+			}
+			auxOff += uint64(auxNext)
+		}
+		defs = append(defs, vd)
+
+		if next == 0 {
+			break
+		}
+		off += uint64(next)
+	}
+	return defs, nil
+}
+
+// applyVersions attaches the version of each symbol in the dynamic symbol
+// table at index, using the SHT_GNU_versym section linked to it
+func applyVersions(sHead []SectionHeader64, sections []Section, index int, symbols []Symbol, endianess uint8) error {
+	versym := -1
+	for i, h := range sHead {
+		if h.SHType == SHTypeEncode["SHT_GNU_versym"] && int(h.SHLink) == index {
+			versym = i
+			break
+		}
+	}
+	if versym < 0 {
+		return nil
+	}
+
+	needs, err := readVersionNeeds(sHead, sections, endianess)
+	if err != nil {
+		return err
+	}
+	defs, err := readVersionDefs(sHead, sections, endianess)
+	if err != nil {
+		return err
+	}
+	type version struct {
+		name    string
+		library string
+	}
+	versions := make(map[uint16]version)
+	for _, vd := range defs {
+		versions[vd.VDNdx] = version{name: vd.Name}
+	}
+	for _, vn := range needs {
+		for _, va := range vn.Versions {
+			versions[va.VNAOther] = version{name: va.Name, library: vn.File}
+		}
+	}
+
+	u16 := readu16Func(endianess)
+	data := sections[versym].Data
+	for i := range symbols {
+		if 2*i+2 > len(data) {
+			return formatErr(int64(sHead[versym].SHOffset), "versym", nil, "0x%X entries for 0x%X symbols", len(data)/2, len(symbols))
+		}
+		val := u16(data, 2*i)
+		symbols[i].VersionIndex = val &^ versymHidden
+		symbols[i].VersionHidden = val&versymHidden != 0
+		if symbols[i].VersionIndex == versymLocal || symbols[i].VersionIndex == versymGlobal {
+			continue
+		}
+		if v, ok := versions[symbols[i].VersionIndex]; ok {
+			symbols[i].Version = v.name
+			symbols[i].Library = v.library
+		}
+	}
+	return nil
+}